@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
-	"strings"
+	"runtime/pprof"
 
 	"github.com/chzyer/readline"
 
@@ -18,10 +21,16 @@ import (
 type LoxApp struct {
 	err        error
 	interpeter interpreter.Interpreter
+	// dumpTokens prints the scanned tokens instead of running the input.
+	dumpTokens bool
+	// dumpAst prints the parsed syntax tree instead of running the input.
+	dumpAst bool
 }
 
 func NewLoxApp() *LoxApp {
-	return &LoxApp{interpeter: interpreter.NewInterpreter()}
+	app := &LoxApp{}
+	app.interpeter = interpreter.NewInterpreter(interpreter.WithErrorReporter(app))
+	return app
 }
 
 // ReportPanic implements loxerrors.ErrReporter.
@@ -36,30 +45,122 @@ func (app *LoxApp) ReportError(err error) {
 	loxerrors.DefaultReportError(os.Stderr, err)
 }
 
+// ReportWarning implements loxerrors.ErrReporter.
+// The warnings don't fail the script, the exit code is kept.
+func (app *LoxApp) ReportWarning(err error) {
+	loxerrors.DefaultReportWarning(os.Stderr, err)
+}
+
 func (app *LoxApp) Main(args []string) int {
-	profile := "default"
-	if len(args) > 0 && strings.HasPrefix(args[0], "-profile=") {
-		profile = strings.TrimPrefix(args[0], "-profile=")
-		args = args[1:]
+	flags := flag.NewFlagSet("golox", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: golox [flags] [script | -e code]\n")
+		flags.PrintDefaults()
+	}
+	profile := flags.String("profile", "default", "resolver profile: default, strict or non-strict")
+	cpuprofile := flags.String("cpuprofile", "", "write cpu profile to `file`")
+	code := flags.String("e", "", "evaluate the `code`, print the result and exit")
+	flags.BoolVar(&app.dumpTokens, "dump-tokens", false, "print the scanned tokens and exit")
+	flags.BoolVar(&app.dumpAst, "dump-ast", false, "print the parsed syntax tree and exit")
+	noBuiltins := flags.Bool("no-builtins", false, "run without the builtin native functions")
+	looseConcat := flags.Bool("loose-concat", false, "allow the string concatenation with any value, e.g. \"x=\" + 5")
+	allowInfinity := flags.Bool("allow-infinity", false, "make the division by zero yield Infinity or NaN instead of the runtime error")
+	format := flags.Bool("fmt", false, "print the formatted script and exit")
+	write := flags.Bool("w", false, "with -fmt, write the formatted script back to the file")
+	lint := flags.Bool("lint", false, "print the linter findings and exit, non-zero exit code on errors")
+	werror := flags.Bool("Werror", false, "with -lint, treat the warnings as errors")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 64
+	}
+	args = flags.Args()
+
+	options := []interpreter.InterpreterOption{interpreter.WithErrorReporter(app)}
+	if *noBuiltins {
+		options = append(options, interpreter.WithoutBuiltins())
+	}
+	if *looseConcat {
+		options = append(options, interpreter.WithLooseStringConcat())
+	}
+	if *allowInfinity {
+		options = append(options, interpreter.WithAllowInfinity())
+	}
+	app.interpeter = interpreter.NewInterpreter(options...)
+
+	if *cpuprofile != "" {
+		stop, err := startCPUProfile(*cpuprofile)
+		if err != nil {
+			app.ReportPanic(err)
+			return app.exitcode(app.err)
+		}
+		defer stop()
 	}
 
 	var err error
-	switch len(args) {
-	case 1:
-		err = app.runFile(profile, args[0])
-	case 0:
-		err = app.runPrompt(profile)
+	var lintFailed bool
+	switch {
+	case *lint && len(args) == 1:
+		lintFailed, err = app.lintFile(*profile, args[0], *werror)
+	case *lint:
+		err = usageError("Usage: golox -lint [-Werror] script")
+	case *format && len(args) == 1:
+		err = app.formatFile(args[0], *write)
+	case *format:
+		err = usageError("Usage: golox -fmt [-w] script")
+	case isFlagSet(flags, "e") && len(args) == 0:
+		err = app.runCode(*profile, *code)
+	case isFlagSet(flags, "e"):
+		err = usageError("Usage: golox [flags] [script | -e code]")
+	case len(args) == 1:
+		err = app.runFile(*profile, args[0])
+	case len(args) == 0:
+		err = app.runPrompt(*profile)
 	default:
-		err = errors.New("Usage: golox [script]")
+		err = usageError("Usage: golox [flags] [script | -e code]")
 	}
 
-	if app.err == nil && err != nil {
+	var exitErr *interpreter.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		// the script requested the exit, nothing to report
+		app.err = exitErr
+	case app.err == nil && err != nil:
 		app.ReportPanic(err)
 	}
+	if app.err == nil && lintFailed {
+		return 65
+	}
 
 	return app.exitcode(app.err)
 }
 
+// startCPUProfile starts the CPU profiling to the file, returns the function to stop it.
+func startCPUProfile(path string) (stop func(), err error) {
+	f, err := os.Create(path) //nolint:gosec // user provided path is expected here
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pprof.StartCPUProfile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		_ = f.Close()
+	}, nil
+}
+
+// isFlagSet reports whether the flag was given on the command line.
+func isFlagSet(flags *flag.FlagSet, name string) (set bool) {
+	flags.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
 func (app *LoxApp) resetError() {
 	app.err = nil
 }
@@ -81,9 +182,18 @@ func (app *LoxApp) runPrompt(profile string) error {
 			return err
 		}
 
-		value, err = app.run(profile, line)
+		stmts, err := app.parse(line)
+		if err == nil && !app.dumping() {
+			value, err = app.execute(profile, stmts)
+		}
+		var exitErr *interpreter.ExitError
+		if errors.As(err, &exitErr) {
+			return err
+		}
 		if err == nil {
-			fmt.Println(value)
+			if !app.dumping() && echoesResult(stmts) {
+				fmt.Println(value)
+			}
 		} else {
 			app.ReportPanic(err)
 			app.resetError()
@@ -91,17 +201,180 @@ func (app *LoxApp) runPrompt(profile string) error {
 	}
 }
 
+func (app *LoxApp) runCode(profile, code string) error {
+	stmts, err := app.parse(code)
+	if err != nil || app.dumping() {
+		return err
+	}
+
+	value, err := app.execute(profile, stmts)
+	if err == nil && echoesResult(stmts) {
+		fmt.Println(value)
+	}
+	return err
+}
+
 func (app *LoxApp) runFile(profile, scriptPath string) error {
-	bytes, err := os.ReadFile(scriptPath) //nolint:gosec // exppected here
+	source, err := readScript(scriptPath)
 	if err != nil {
 		return err
 	}
 
-	_, err = app.run(profile, string(bytes))
+	_, err = app.run(profile, source)
 	return err
 }
 
+// usageError is the invalid combination of the command line flags and arguments.
+type usageError string
+
+// Error implements error.
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// scriptError is the script file which can't be read, e.g. it doesn't exist.
+type scriptError struct {
+	path string
+	err  error
+}
+
+// Error implements error.
+func (e *scriptError) Error() string {
+	return fmt.Sprintf("golox: cannot open '%s': %v", e.path, e.err)
+}
+
+// Unwrap returns the underlying file system error.
+func (e *scriptError) Unwrap() error {
+	return e.err
+}
+
+// readScript reads the script file, the read failure is returned as *scriptError.
+func readScript(scriptPath string) (string, error) {
+	bytes, err := os.ReadFile(scriptPath) //nolint:gosec // exppected here
+	if err != nil {
+		// the path is in the message already
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return "", &scriptError{path: scriptPath, err: err}
+	}
+	return string(bytes), nil
+}
+
+// formatFile prints the formatted script, or writes it back to the file.
+func (app *LoxApp) formatFile(scriptPath string, write bool) error {
+	source, err := readScript(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := scanner.NewScanner(source, app, scanner.WithComments()).Scan()
+	if err != nil {
+		return err
+	}
+	p := parser.NewParser(tokens, app)
+	stmts, err := p.Parse()
+	if err != nil {
+		return err
+	}
+
+	formatted := parser.Format(stmts, parser.WithComments(p.Comments()))
+	if write {
+		// keeps the original file permissions
+		info, err := os.Stat(scriptPath)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(scriptPath, []byte(formatted), info.Mode().Perm())
+	}
+	fmt.Print(formatted)
+	return nil
+}
+
+// lintFile prints the linter findings, reports whether the script fails the lint.
+// The warnings fail the lint only with werror.
+func (app *LoxApp) lintFile(profile, scriptPath string, werror bool) (failed bool, err error) {
+	source, err := readScript(scriptPath)
+	if err != nil {
+		return false, err
+	}
+
+	tokens, err := scanner.NewScanner(source, app).Scan()
+	if err != nil {
+		return false, err
+	}
+	stmts, err := parser.NewParser(tokens, app).Parse()
+	if err != nil {
+		return false, err
+	}
+
+	reporter := lintReporter{w: os.Stdout}
+	for _, finding := range interpreter.Lint(stmts, profile) {
+		if finding.Severity == interpreter.SeverityError {
+			reporter.ReportError(finding)
+		} else {
+			reporter.ReportWarning(finding)
+		}
+		failed = failed || werror || finding.Severity == interpreter.SeverityError
+	}
+	return failed, nil
+}
+
+// lintReporter prints the linter findings as is, they are labeled with the severity already.
+type lintReporter struct {
+	w io.Writer
+}
+
+// ReportPanic implements loxerrors.ErrReporter.
+func (r lintReporter) ReportPanic(err error) {
+	loxerrors.DefaultReportPanic(r.w, err)
+}
+
+// ReportError implements loxerrors.ErrReporter.
+func (r lintReporter) ReportError(err error) {
+	loxerrors.DefaultReportError(r.w, err)
+}
+
+// ReportWarning implements loxerrors.ErrReporter.
+func (r lintReporter) ReportWarning(err error) {
+	fmt.Fprintf(r.w, "%v\n", err)
+}
+
+// echoesResult reports whether the REPL prints the result of the statements:
+// only if the last one is an expression statement, except for the assignments and the increments.
+func echoesResult(stmts []parser.Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	stmt, ok := stmts[len(stmts)-1].(*parser.StmtExpression)
+	if !ok {
+		return false
+	}
+	switch stmt.Expression.(type) {
+	case *parser.ExprAssign, *parser.ExprSet, *parser.ExprPostfix:
+		return false
+	default:
+		return true
+	}
+}
+
 func (app *LoxApp) run(profile, input string) (any, error) {
+	stmts, err := app.parse(input)
+	if err != nil || app.dumping() {
+		return nil, err
+	}
+
+	return app.execute(profile, stmts)
+}
+
+// dumping reports whether the tokens or the syntax tree are printed instead of running the code.
+func (app *LoxApp) dumping() bool {
+	return app.dumpTokens || app.dumpAst
+}
+
+// parse scans and parses the input, the tokens or the syntax tree are printed if dumping.
+func (app *LoxApp) parse(input string) ([]parser.Stmt, error) {
 	s := scanner.NewScanner(input, app)
 
 	tokens, err := s.Scan()
@@ -109,12 +382,26 @@ func (app *LoxApp) run(profile, input string) (any, error) {
 		return nil, err
 	}
 
+	if app.dumpTokens {
+		for _, tok := range tokens {
+			fmt.Printf("%#v\n", tok)
+		}
+		return nil, nil
+	}
+
 	p := parser.NewParser(tokens, app)
 	stmts, err := p.Parse()
 	if err != nil {
 		return nil, err
 	}
 
+	if app.dumpAst {
+		fmt.Print(parser.NewAstPrinter().Print(stmts))
+	}
+	return stmts, nil
+}
+
+func (app *LoxApp) execute(profile string, stmts []parser.Stmt) (any, error) {
 	if err := app.resolve(profile, stmts); err != nil {
 		return nil, err
 	}
@@ -128,7 +415,7 @@ func (app *LoxApp) resolve(profile string, stmts []parser.Stmt) error {
 }
 
 func (app *LoxApp) interpret(stmts []parser.Stmt) (any, error) {
-	return app.interpeter.Interpret(stmts)
+	return app.interpeter.Interpret(context.Background(), stmts)
 }
 
 func (app *LoxApp) exitcode(err error) int {
@@ -154,7 +441,15 @@ func (app *LoxApp) _exitcode(err error) (bool, int) {
 		return false, 0
 	}
 
-	switch err.(type) { //nolint:errorlint // exppected here
+	switch err := err.(type) { //nolint:errorlint // exppected here
+	case *interpreter.ExitError:
+		return true, err.Code
+	case usageError:
+		// EX_USAGE, the command was used incorrectly
+		return true, 64
+	case *scriptError:
+		// EX_NOINPUT, the input file did not exist or was not readable
+		return true, 66
 	case *loxerrors.ParserError, *loxerrors.ScannerError:
 		return true, 65
 	case *loxerrors.RuntimeError:
@@ -164,4 +459,7 @@ func (app *LoxApp) _exitcode(err error) (bool, int) {
 	}
 }
 
-var _ loxerrors.ErrReporter = (*LoxApp)(nil)
+var (
+	_ loxerrors.ErrReporter = (*LoxApp)(nil)
+	_ loxerrors.ErrReporter = lintReporter{}
+)
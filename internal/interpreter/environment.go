@@ -9,20 +9,51 @@ import (
 
 type environment struct {
 	enclosing *environment
-	values    map[string]any
+	// values are the globals by name.
+	values map[string]any
+	// slots are the local values in the definition order, indexed with the slots the resolver assigned.
+	slots []any
+	// local environments (see Nest) keep the values in the slots, the globals in the values map.
+	local bool
+	// readonly environment is created with all its values by newReadonlyEnvironment,
+	// only the globals and the local environments are defined in.
+	readonly bool
+	// consts are the names defined with DefineConst, allocated on the first one.
+	consts map[string]bool
 }
 
 func NewEnvironment() *environment {
 	return &environment{}
 }
 
+// newReadonlyEnvironment returns the read-only environment with the values.
+// Assignments to the read-only values are defined in the nested environment instead.
+func newReadonlyEnvironment(values map[string]any) *environment {
+	return &environment{values: values, readonly: true}
+}
+
 func (e *environment) Define(name string, value any) {
+	if e.local {
+		e.slots = append(e.slots, value)
+		return
+	}
 	if e.values == nil {
 		e.values = make(map[string]any)
 	}
+	// the global redefinition drops the constness
+	delete(e.consts, name)
 	e.values[name] = value
 }
 
+// DefineConst defines the value which can't be assigned, the assignment fails with loxerrors.ErrRuntimeCantAssignConstant.
+func (e *environment) DefineConst(name string, value any) {
+	e.Define(name, value)
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+}
+
 func (e *environment) Get(name *token.Token) (any, error) {
 	if value, ok := e.values[name.Lexeme]; ok {
 		return value, nil
@@ -35,43 +66,77 @@ func (e *environment) Get(name *token.Token) (any, error) {
 	return nil, e.undefinedVariable(name)
 }
 
+// Undefine removes the name defined in this environment, the enclosing ones are not affected.
+// Reports whether the name was defined.
+func (e *environment) Undefine(name string) bool {
+	_, ok := e.values[name]
+	delete(e.values, name)
+	delete(e.consts, name)
+	return ok
+}
+
+// Has reports whether the name is defined in the environment or any of the enclosing ones.
+func (e *environment) Has(name string) bool {
+	for env := e; env != nil; env = env.enclosing {
+		if _, ok := env.values[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *environment) Assign(name *token.Token, value any) error {
 	if _, ok := e.values[name.Lexeme]; ok {
+		if e.consts[name.Lexeme] {
+			return e.constantAssignment(name)
+		}
 		e.values[name.Lexeme] = value
 		return nil
 	}
 
 	if e.enclosing != nil {
+		if _, ok := e.enclosing.values[name.Lexeme]; ok && e.enclosing.readonly {
+			if e.enclosing.consts[name.Lexeme] {
+				return e.constantAssignment(name)
+			}
+			// copy-on-write, shadows the read-only value
+			e.Define(name.Lexeme, value)
+			return nil
+		}
 		return e.enclosing.Assign(name, value)
 	}
 
 	return e.undefinedVariable(name)
 }
 
-func (e *environment) GetAt(distance int, name string) (any, error) {
+// GetAt returns the local value at the resolved scope distance and slot, the name is for the error message.
+func (e *environment) GetAt(distance, slot int, name string) (any, error) {
 	depth := e.ancestor(distance)
-	if value, ok := depth.values[name]; ok {
-		return value, nil
+	if slot < len(depth.slots) {
+		return depth.slots[slot], nil
 	}
 
 	err := fmt.Errorf("%w '%s'.", loxerrors.ErrRuntimeUndefinedVariable, name)
 	return nil, err
 }
 
-func (e *environment) AssignAt(distance int, name *token.Token, value any) (any, error) {
+// AssignAt sets the local value at the resolved scope distance and slot.
+func (e *environment) AssignAt(distance, slot int, name *token.Token, value any) (any, error) {
 	depth := e.ancestor(distance)
-	if depth.values == nil {
-		depth.values = make(map[string]any)
+	if slot < len(depth.slots) {
+		if depth.consts[name.Lexeme] {
+			return nil, depth.constantAssignment(name)
+		}
+		depth.slots[slot] = value
+		return value, nil
 	}
-	depth.values[name.Lexeme] = value
 
-	return value, nil
+	return nil, depth.undefinedVariable(name)
 }
 
+// Nest returns the local environment enclosed by this one.
 func (e *environment) Nest() *environment {
-	env := NewEnvironment()
-	env.enclosing = e
-	return env
+	return &environment{enclosing: e, local: true}
 }
 
 func (e *environment) Enclosing() *environment {
@@ -93,6 +158,11 @@ func (e *environment) undefinedVariable(name *token.Token) error {
 	return loxerrors.NewRuntimeError(name, err)
 }
 
+func (e *environment) constantAssignment(name *token.Token) error {
+	err := fmt.Errorf("%w '%s'.", loxerrors.ErrRuntimeCantAssignConstant, name.Lexeme)
+	return loxerrors.NewRuntimeError(name, err)
+}
+
 func (e *environment) String() string {
 	w := ""
 
@@ -101,6 +171,9 @@ func (e *environment) String() string {
 		for k, v := range self.values {
 			w += fmt.Sprintf("%s=%v,", k, v)
 		}
+		for slot, v := range self.slots {
+			w += fmt.Sprintf("%d=%v,", slot, v)
+		}
 		w += "}"
 		if self.enclosing != nil {
 			w += " -> "
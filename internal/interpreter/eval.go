@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+// Result is the detailed outcome of a script evaluation.
+type Result struct {
+	// Value is the value of the last evaluated statement.
+	Value any
+	// Printable reports whether the last statement is an expression statement,
+	// i.e. the Value is meaningful to display.
+	Printable bool
+	// Stdout is the output printed by the script.
+	Stdout string
+	// Diagnostics are all the errors reported while evaluating the script.
+	Diagnostics []error
+}
+
+// Eval scans, parses, resolves and interprets the source with a new interpreter.
+// Returns the stringified result of the last statement and an error if any.
+func Eval(source string, options ...InterpreterOption) (string, error) {
+	return NewInterpreter(options...).Eval(source)
+}
+
+// EvalDetailed evaluates the source with a new interpreter.
+// Captures the script output and collects the diagnostics into the Result.
+// The Result is never nil, the error is the same as the one returned by Eval.
+func EvalDetailed(source string, options ...InterpreterOption) (*Result, error) {
+	stdout := new(strings.Builder)
+	reporter := loxerrors.NewCollectingReporter()
+	options = append(options, WithStdout(stdout), WithErrorReporter(reporter))
+
+	value, printable, err := NewInterpreter(options...).eval(source)
+
+	return &Result{
+		Value:       value,
+		Printable:   printable,
+		Stdout:      stdout.String(),
+		Diagnostics: diagnostics(reporter.All(), err),
+	}, err
+}
+
+// Eval implements Interpreter.
+func (i *interpreter) Eval(source string) (string, error) {
+	value, _, err := i.eval(source)
+	if err != nil {
+		return "", err
+	}
+
+	return i.stringify(value)
+}
+
+// EvalExpr implements Interpreter.
+func (i *interpreter) EvalExpr(source string) (any, error) {
+	tokens, err := scanner.NewScanner(source, i.ErrReporter).Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parser.NewParser(tokens, i.ErrReporter).ParseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := []parser.Stmt{&parser.StmtExpression{Expression: expr}}
+	if err = NewResolver(i, "default").Resolve(stmts); err != nil {
+		return nil, err
+	}
+
+	return i.interpret(context.Background(), stmts)
+}
+
+func (i *interpreter) eval(source string) (value any, printable bool, err error) {
+	tokens, err := scanner.NewScanner(source, i.ErrReporter).Scan()
+	if err != nil {
+		return nil, false, err
+	}
+
+	stmts, err := parser.NewParser(tokens, i.ErrReporter).Parse()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err = NewResolver(i, "default").Resolve(stmts); err != nil {
+		return nil, false, err
+	}
+
+	if value, err = i.interpret(context.Background(), stmts); err != nil {
+		return nil, false, err
+	}
+
+	if len(stmts) > 0 {
+		_, printable = stmts[len(stmts)-1].(*parser.StmtExpression)
+	}
+
+	return value, printable, nil
+}
+
+// diagnostics adds the returned error to the reported ones, unless it's a summary of the already reported errors.
+func diagnostics(reported []error, err error) []error {
+	if err == nil || errors.Is(err, loxerrors.ErrScanError) || errors.Is(err, loxerrors.ErrParseError) {
+		return reported
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return append(reported, joined.Unwrap()...)
+	}
+
+	return append(reported, err)
+}
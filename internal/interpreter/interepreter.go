@@ -1,9 +1,16 @@
 package interpreter
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
 	"github.com/leonardinius/golox/internal/parser"
@@ -15,20 +22,76 @@ var (
 	errContinue = errors.New("eval:continue")
 )
 
+// labeledJumpError is the `break label;` or `continue label;`, the loop with the label catches it.
+type labeledJumpError struct {
+	// jump is errBreak or errContinue.
+	jump  error
+	label string
+}
+
+// Error implements error.
+func (e *labeledJumpError) Error() string {
+	return e.jump.Error() + " " + e.label
+}
+
+// Unwrap returns the errBreak or errContinue sentinel.
+func (e *labeledJumpError) Unwrap() error {
+	return e.jump
+}
+
+// loopJump returns errBreak or errContinue if the error targets the loop, otherwise the error as is.
+func loopJump(err error, label *token.Token) error {
+	var jump *labeledJumpError
+	if errors.As(err, &jump) && label != nil && jump.label == label.Lexeme {
+		return jump.jump
+	}
+	return err
+}
+
 type Interpreter interface {
 	// Interpret interprets the given statements.
 	// Returns the stringified result of the last statement and an error if any.
 	// The error is nil if the statement is valid.
+	// The execution stops with the context error once the context is done.
+	//
+	// Not thread safe.
+	Interpret(ctx context.Context, stmts []parser.Stmt) (string, error)
+
+	// EvalValue interprets the given statements.
+	// Returns the value of the last statement as is (float64, string, bool, nil, ...), not stringified.
+	// The execution stops with the context error once the context is done.
 	//
 	// Not thread safe.
-	Interpret(stmts []parser.Stmt) (string, error)
+	EvalValue(ctx context.Context, stmts []parser.Stmt) (any, error)
 
 	// Evaluate evaluates the given statement.
 	// Returns an error if any.
 	// The error is nil if the statement is valid.
+	// The execution stops with the context error once the context is done.
+	//
+	// Not thread safe.
+	Evaluate(ctx context.Context, stmt parser.Stmt) (any, error)
+
+	// Eval scans, parses, resolves and interprets the given source.
+	// Returns the stringified result of the last statement and an error if any.
 	//
 	// Not thread safe.
-	Evaluate(stmt parser.Stmt) (any, error)
+	Eval(source string) (string, error)
+
+	// EvalExpr scans, parses, resolves and evaluates the single expression, e.g. `1 + 2`.
+	// Returns the value as is (float64, string, bool, nil, ...), not stringified.
+	//
+	// Not thread safe.
+	EvalExpr(source string) (any, error)
+
+	// RegisterNativeFunction defines the host function as a global, replacing the existing global with the name.
+	// The arguments count is checked on call, unless the arity is negative (varargs).
+	// See WithNativeFunction to define the functions on the interpreter creation.
+	RegisterNativeFunction(name string, arity int, fn func(args ...any) (any, error))
+
+	// Coverage returns the copy of the source lines of the executed statements with the execution counts.
+	// Returns nil unless the coverage is enabled with WithCoverage.
+	Coverage() map[int]int
 }
 
 type interpreter struct {
@@ -38,15 +101,61 @@ type interpreter struct {
 	Stdout      io.Writer
 	Stderr      io.Writer
 	ErrReporter loxerrors.ErrReporter
-	Locals      map[parser.Expr]int
+	Locals      map[parser.Expr]Local
+	opts        *interpreterOpts
+	ctx         context.Context
+	// values being converted with user defined toString(), guards against recursion.
+	stringifying map[any]bool
+	// active calls to the Lox functions and classes, innermost last.
+	frames []callFrame
+	// buffered Stdin, shared by the read_line() calls.
+	stdinLines *bufio.Reader
+	// executed statements count per line, and the statements lines cache; nil unless WithCoverage.
+	coverage  map[int]int
+	stmtLines map[parser.Stmt]int
+	// seeded random numbers source; nil unless WithRandSeed, the global source is used.
+	random *rand.Rand
+}
+
+// Local is the resolved local variable: the distance to its scope, and the slot in the scope.
+type Local struct {
+	Depth, Slot int
+}
+
+type callFrame struct {
+	function string
+	callSite *token.Token
 }
 
 func NewInterpreter(options ...InterpreterOption) *interpreter {
 	opts := newInterpreterOpts(options...)
 	globals := opts.globals
-	globals.Define("Array", NativeFunction1(StdFnCreateArray))
-	globals.Define("clock", NativeFunction0(StdFnTime))
-	globals.Define("pprint", NativeFunctionVarArgs(StdFnPPrint))
+	if globals.enclosing == nil && !opts.noBuiltins {
+		// user globals are nested on the builtins shared by all interpreters.
+		globals.enclosing = stdBuiltins()
+	}
+	if opts.fileAccess {
+		for name, function := range fileBuiltins {
+			globals.Define(name, function)
+		}
+	}
+	for _, native := range opts.natives {
+		globals.Define(native.name, native.function)
+	}
+	for name, value := range opts.globalValues {
+		globals.Define(name, value)
+	}
+
+	var random *rand.Rand
+	if opts.randSeed != nil {
+		random = rand.New(rand.NewSource(*opts.randSeed)) //nolint:gosec // not for the security sensitive use
+	}
+
+	var coverage map[int]int
+	var stmtLines map[parser.Stmt]int
+	if opts.coverage {
+		coverage, stmtLines = make(map[int]int), make(map[parser.Stmt]int)
+	}
 
 	return &interpreter{
 		Globals:     globals,
@@ -55,44 +164,204 @@ func NewInterpreter(options ...InterpreterOption) *interpreter {
 		Stdout:      opts.stdout,
 		Stderr:      opts.stderr,
 		ErrReporter: opts.reporter,
-		Locals:      make(map[parser.Expr]int),
+		Locals:      make(map[parser.Expr]Local),
+		opts:        opts,
+		ctx:         context.Background(),
+		coverage:    coverage,
+		stmtLines:   stmtLines,
+		random:      random,
 	}
 }
 
+// RegisterNativeFunction implements Interpreter.
+func (i *interpreter) RegisterNativeFunction(name string, arity int, fn func(args ...any) (any, error)) {
+	i.Globals.Define(name, hostFunction(arity, fn))
+}
+
+// Coverage implements Interpreter.
+func (i *interpreter) Coverage() map[int]int {
+	return maps.Clone(i.coverage)
+}
+
 // Interpret implements Interpreter.
-func (i *interpreter) Interpret(stmts []parser.Stmt) (string, error) {
-	var v any
-	var err error
+func (i *interpreter) Interpret(ctx context.Context, stmts []parser.Stmt) (string, error) {
+	v, err := i.interpret(ctx, stmts)
+	if err != nil {
+		return "", err
+	}
+
+	return i.stringify(v)
+}
+
+// EvalValue implements Interpreter.
+func (i *interpreter) EvalValue(ctx context.Context, stmts []parser.Stmt) (any, error) {
+	return i.interpret(ctx, stmts)
+}
+
+// Evaluate implements Interpreter.
+func (i *interpreter) Evaluate(ctx context.Context, stmt parser.Stmt) (any, error) {
+	return i.interpret(ctx, []parser.Stmt{stmt})
+}
+
+// interpret executes the statements, the loops and calls check the context (and the timeout) is not done.
+func (i *interpreter) interpret(ctx context.Context, stmts []parser.Stmt) (value any, err error) {
+	defer i.setContext(i.setContext(ctx))
+	if i.opts.timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, i.opts.timeout)
+		defer cancel()
+		defer i.setContext(i.setContext(ctx))
+	}
 
 	for _, stmt := range stmts {
-		if v, err = i.Evaluate(stmt); err != nil {
-			return "", err
+		if value, err = i.execute(stmt); err != nil {
+			return nil, err
 		}
 	}
 
-	return i.stringify(v), nil
+	return value, nil
 }
 
-// Evaluate implements Interpreter.
-func (i *interpreter) Evaluate(stmt parser.Stmt) (any, error) {
-	return i.execute(stmt)
+func (i *interpreter) print(v ...any) error {
+	line, err := i.join(" ", v...)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(i.Stdout, line)
+	if i.opts.autoFlush {
+		i.flush()
+	}
+	return nil
 }
 
-func (i *interpreter) print(v ...any) {
-	for i, vv := range v {
-		if vv == nil {
-			v[i] = "nil"
+// join displays the values separated by the sep.
+func (i *interpreter) join(sep string, v ...any) (string, error) {
+	values := make([]string, len(v))
+	for index, value := range v {
+		s, err := i.display(value)
+		if err != nil {
+			return "", err
 		}
+		values[index] = s
 	}
+	return strings.Join(values, sep), nil
+}
 
-	_, _ = fmt.Fprintln(i.Stdout, v...)
+// flush flushes the stdout, if the writer supports it.
+func (i *interpreter) flush() {
+	switch w := i.Stdout.(type) {
+	case interface{ Flush() error }:
+		_ = w.Flush()
+	case interface{ Flush() }:
+		w.Flush()
+	}
 }
 
-func (i *interpreter) stringify(v any) string {
+// display returns the value as it is printed, the errors of the user defined toString() are returned.
+func (i *interpreter) display(v any) (string, error) {
 	if v == nil {
+		return "nil", nil
+	}
+	switch v := v.(type) {
+	case float64:
+		return formatNumber(v), nil
+	case *StdArray:
+		return i.displayNested(v, "[...]", func() (string, error) { return v.format(i.display) })
+	case *StdMap:
+		return i.displayNested(v, "{...}", func() (string, error) { return v.format(i.display) })
+	}
+	if s, ok, err := i.toString(v); ok || err != nil {
+		return s, err
+	}
+	return fmt.Sprint(v), nil
+}
+
+// displayNested formats the container elements with display, the container nested in itself is shown as cyclic.
+func (i *interpreter) displayNested(v any, cyclic string, format func() (string, error)) (string, error) {
+	if i.stringifying[v] {
+		return cyclic, nil
+	}
+	if i.stringifying == nil {
+		i.stringifying = make(map[any]bool)
+	}
+	i.stringifying[v] = true
+	defer delete(i.stringifying, v)
+	return format()
+}
+
+func (i *interpreter) stringify(v any) (string, error) {
+	if v == nil {
+		return "nil", nil
+	}
+	switch v.(type) {
+	case float64, *StdArray, *StdMap:
+		return i.display(v)
+	}
+	if s, ok, err := i.toString(v); ok || err != nil {
+		return s, err
+	}
+	return fmt.Sprintf("%#v", v), nil
+}
+
+// formatValue formats the value as display does, without calling the user defined toString() methods.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
 		return "nil"
+	case float64:
+		return formatNumber(v)
 	}
-	return fmt.Sprintf("%#v", v)
+	return fmt.Sprint(v)
+}
+
+// formatElement is formatValue for the container format funcs, it never fails.
+func formatElement(v any) (string, error) {
+	return formatValue(v), nil
+}
+
+// formatNumber formats the number as Lox does: integral values without the decimal point (`4`),
+// the fractions with the shortest representation (`4.5`), the huge values in exponent form.
+func formatNumber(n float64) string {
+	switch {
+	case math.IsNaN(n):
+		return "NaN"
+	case math.IsInf(n, 1):
+		return "Infinity"
+	case math.IsInf(n, -1):
+		return "-Infinity"
+	case n == math.Trunc(n) && math.Abs(n) < 1e21:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+}
+
+// toString calls the user defined toString() method of the instance or class, if any.
+// Falls back (ok=false) to the default representation on recursion or non-string results, the call errors are returned.
+func (i *interpreter) toString(v any) (s string, ok bool, err error) {
+	var method *LoxFunction
+	var instance LoxInstance
+	switch v := v.(type) {
+	case *objectInstance:
+		method, instance = v.Class.FindMethod("toString"), v
+	case *LoxClass:
+		method, instance = v.MetaClass.FindMethod("toString"), v
+	}
+	if method == nil || method.Arity() != 0 || i.stringifying[v] {
+		return "", false, nil
+	}
+
+	if i.stringifying == nil {
+		i.stringifying = make(map[any]bool)
+	}
+	i.stringifying[v] = true
+	defer delete(i.stringifying, v)
+
+	value, err := method.Bind(instance).Call(i, nil)
+	if err != nil {
+		return "", false, err
+	}
+	s, ok = value.(string)
+	return s, ok, nil
 }
 
 // VisitExpression implements parser.StmtVisitor.
@@ -100,6 +369,44 @@ func (i *interpreter) VisitStmtExpression(expr *parser.StmtExpression) (any, err
 	return i.evaluate(expr.Expression)
 }
 
+// VisitStmtForEach implements parser.StmtVisitor.
+func (i *interpreter) VisitStmtForEach(stmtForEach *parser.StmtForEach) (any, error) {
+	iterable, err := i.evaluate(stmtForEach.Iterable)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := iterable.(*StdArray)
+	if !ok {
+		return i.returnRuntimeError(stmtForEach.In, loxerrors.ErrRuntimeForEachIterableMustBeArray)
+	}
+
+	oldEnv := i.Env
+	defer i.setEnv(oldEnv)
+
+	var value any
+	for _, element := range array.values {
+		if err = i.checkInterrupted(); err != nil {
+			return nil, err
+		}
+
+		// fresh scope per iteration, the closures capture the current element
+		i.Env = oldEnv.Nest()
+		i.Env.Define(stmtForEach.Name.Lexeme, element)
+		if value, err = i.execute(stmtForEach.Body); err != nil {
+			switch err = loopJump(err, stmtForEach.Label); {
+			case err == errBreak:
+				return nil, errNilnil
+			case err == errContinue:
+				err = nil
+			default:
+				return nil, err
+			}
+		}
+	}
+
+	return value, nil
+}
+
 // VisitStmtFunction implements parser.StmtVisitor.
 func (i *interpreter) VisitStmtFunction(stmtFunction *parser.StmtFunction) (any, error) {
 	function := NewLoxFunction(stmtFunction.Name, stmtFunction.Fn, i.Env, false)
@@ -126,11 +433,15 @@ func (i *interpreter) VisitStmtIf(stmtIf *parser.StmtIf) (any, error) {
 
 // VisitPrint implements parser.StmtVisitor.
 func (i *interpreter) VisitStmtPrint(expr *parser.StmtPrint) (any, error) {
-	value, err := i.evaluate(expr.Expression)
-	if err == nil {
-		i.print(value)
+	values := make([]any, len(expr.Expressions))
+	for index, expression := range expr.Expressions {
+		value, err := i.evaluate(expression)
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
 	}
-	return nil, err
+	return nil, i.print(values...)
 }
 
 // VisitStmtReturn implements parser.StmtVisitor.
@@ -154,7 +465,11 @@ func (i *interpreter) VisitStmtVar(stmt *parser.StmtVar) (any, error) {
 		}
 	}
 
-	i.Env.Define(stmt.Name.Lexeme, value)
+	if stmt.IsConst {
+		i.Env.DefineConst(stmt.Name.Lexeme, value)
+	} else {
+		i.Env.Define(stmt.Name.Lexeme, value)
+	}
 
 	return nil, errNilnil
 }
@@ -166,6 +481,10 @@ func (i *interpreter) VisitStmtWhile(stmtWhile *parser.StmtWhile) (any, error) {
 	var err error
 
 	for err == nil {
+		if err = i.checkInterrupted(); err != nil {
+			break
+		}
+
 		if condition, err = i.evaluate(stmtWhile.Condition); err != nil {
 			break
 		}
@@ -175,7 +494,7 @@ func (i *interpreter) VisitStmtWhile(stmtWhile *parser.StmtWhile) (any, error) {
 		}
 
 		if value, err = i.execute(stmtWhile.Body); err != nil {
-			switch {
+			switch err = loopJump(err, stmtWhile.Label); {
 			case err == errBreak:
 				// returns immediately
 				return nil, errNilnil
@@ -196,10 +515,16 @@ func (i *interpreter) VisitStmtFor(stmtFor *parser.StmtFor) (any, error) {
 	var err error
 
 	if stmtFor.Initializer != nil {
+		// the initializer variables get their own environment, as the resolver gives them their own scope slots
+		defer i.setEnv(i.setEnv(i.Env.Nest()))
 		_, err = i.execute(stmtFor.Initializer)
 	}
 
 	for err == nil {
+		if err = i.checkInterrupted(); err != nil {
+			break
+		}
+
 		if condition, err = i.evaluate(stmtFor.Condition); err != nil {
 			break
 		}
@@ -209,7 +534,7 @@ func (i *interpreter) VisitStmtFor(stmtFor *parser.StmtFor) (any, error) {
 		}
 
 		if value, err = i.execute(stmtFor.Body); err != nil {
-			switch {
+			switch err = loopJump(err, stmtFor.Label); {
 			case err == errBreak:
 				// returns immediately
 				return nil, errNilnil
@@ -229,20 +554,89 @@ func (i *interpreter) VisitStmtFor(stmtFor *parser.StmtFor) (any, error) {
 
 // VisitStmtBreak implements parser.StmtVisitor.
 func (*interpreter) VisitStmtBreak(stmtBreak *parser.StmtBreak) (any, error) {
+	if stmtBreak.Label != nil {
+		return nil, &labeledJumpError{jump: errBreak, label: stmtBreak.Label.Lexeme}
+	}
 	return nil, errBreak
 }
 
 // VisitStmtContinue implements parser.StmtVisitor.
 func (*interpreter) VisitStmtContinue(stmtContinue *parser.StmtContinue) (any, error) {
+	if stmtContinue.Label != nil {
+		return nil, &labeledJumpError{jump: errContinue, label: stmtContinue.Label.Lexeme}
+	}
 	return nil, errContinue
 }
 
+// VisitStmtEmpty implements parser.StmtVisitor.
+func (*interpreter) VisitStmtEmpty(stmtEmpty *parser.StmtEmpty) (any, error) {
+	return nil, errNilnil
+}
+
 // VisitStmtBlock implements parser.StmtVisitor.
 func (i *interpreter) VisitStmtBlock(block *parser.StmtBlock) (any, error) {
 	newEnv := i.Env.Nest()
 	return i.executeBlock(newEnv, block.Statements)
 }
 
+// VisitStmtTry implements parser.StmtVisitor.
+func (i *interpreter) VisitStmtTry(stmtTry *parser.StmtTry) (value any, err error) {
+	value, err = i.executeBlock(i.Env.Nest(), stmtTry.Body)
+
+	if err != nil && stmtTry.CatchName != nil {
+		if caught, ok := i.caught(err); ok {
+			env := i.Env.Nest()
+			env.Define(stmtTry.CatchName.Lexeme, caught)
+			value, err = i.executeBlock(env, stmtTry.CatchBody)
+		}
+	}
+
+	if stmtTry.FinallyBody != nil {
+		// finally errors (and control flow) take precedence over the try/catch outcome
+		if _, finallyErr := i.executeBlock(i.Env.Nest(), stmtTry.FinallyBody); finallyErr != nil {
+			return nil, finallyErr
+		}
+	}
+
+	return value, err
+}
+
+// caught returns the value to bind to the catch variable: the thrown value,
+// or the message of the runtime error. Control flow and interruption errors are not caught.
+func (i *interpreter) caught(err error) (any, bool) {
+	var hookErr *StepHookError
+	if errors.As(err, &hookErr) {
+		return nil, false
+	}
+
+	var thrown *ThrowValueError
+	if errors.As(err, &thrown) {
+		return thrown.Value, true
+	}
+
+	var runtimeErr *loxerrors.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr.Unwrap().Error(), true
+	}
+
+	return nil, false
+}
+
+// VisitStmtThrow implements parser.StmtVisitor.
+func (i *interpreter) VisitStmtThrow(stmtThrow *parser.StmtThrow) (any, error) {
+	value, err := i.evaluate(stmtThrow.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := i.display(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.returnRuntimeError(stmtThrow.Keyword, &ThrowValueError{Value: value, message: message})
+}
+
 // VisitStmtClass implements parser.StmtVisitor.
 func (i *interpreter) VisitStmtClass(stmtClass *parser.StmtClass) (any, error) {
 	var superClass *LoxClass
@@ -259,8 +653,6 @@ func (i *interpreter) VisitStmtClass(stmtClass *parser.StmtClass) (any, error) {
 		}
 	}
 	env := i.Env
-	env.Define(stmtClass.Name.Lexeme, nil)
-
 	if superClass != nil {
 		env = env.Nest()
 		env.Define("super", superClass)
@@ -270,18 +662,55 @@ func (i *interpreter) VisitStmtClass(stmtClass *parser.StmtClass) (any, error) {
 	methods := make(map[string]*LoxFunction)
 	for _, method := range stmtClass.ClassMethods {
 		function := NewLoxFunction(method.Name, method.Fn, env, false)
+		function.IsGetter = method.IsGetter
 		classMethods[method.Name.Lexeme] = function
 	}
 	for _, method := range stmtClass.Methods {
 		function := NewLoxFunction(method.Name, method.Fn, env, method.Name.Lexeme == "init")
+		function.IsGetter = method.IsGetter
 		methods[method.Name.Lexeme] = function
 	}
 
 	class := NewLoxClass(stmtClass.Name.Lexeme, superClass, methods, classMethods)
-	if superClass != nil {
-		env = env.Enclosing()
+	class.Fields, class.FieldsEnv = stmtClass.Fields, env
+	// defined once complete, the methods refer to the class only when called
+	i.Env.Define(stmtClass.Name.Lexeme, class)
+	return nil, errNilnil
+}
+
+// VisitExprArray implements parser.ExprVisitor.
+func (i *interpreter) VisitExprArray(exprArray *parser.ExprArray) (any, error) {
+	values := make([]any, len(exprArray.Elements))
+	for index, element := range exprArray.Elements {
+		value, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
 	}
-	return nil, env.Assign(stmtClass.Name, class)
+
+	return NewStdArray(values), nil
+}
+
+// VisitExprMap implements parser.ExprVisitor.
+func (i *interpreter) VisitExprMap(exprMap *parser.ExprMap) (any, error) {
+	m := NewStdMap()
+	for index, keyExpr := range exprMap.Keys {
+		key, err := i.evaluate(keyExpr)
+		if err != nil {
+			return nil, err
+		}
+		if err = m.checkKey(exprMap.Brace, key); err != nil {
+			return nil, err
+		}
+		value, err := i.evaluate(exprMap.Values[index])
+		if err != nil {
+			return nil, err
+		}
+		m.entries.set(key, value)
+	}
+
+	return m, nil
 }
 
 // VisitExprGet implements parser.ExprVisitor.
@@ -297,7 +726,21 @@ func (i *interpreter) VisitExprGet(exprGet *parser.ExprGet) (any, error) {
 		return nil, err
 	}
 
-	return instance.(LoxInstance).Get(exprGet.Name)
+	value, err := instance.(LoxInstance).Get(exprGet.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.invokeGetter(value)
+}
+
+// invokeGetter calls the getter method immediately, other values are returned as is.
+func (i *interpreter) invokeGetter(value any) (any, error) {
+	if method, ok := value.(*LoxFunction); ok && method.IsGetter {
+		return method.Call(i, nil)
+	}
+
+	return value, nil
 }
 
 // VisitVariable implements parser.ExprVisitor.
@@ -312,7 +755,52 @@ func (i *interpreter) VisitExprAssign(assign *parser.ExprAssign) (any, error) {
 		return nil, err
 	}
 
-	return i.assignVariable(assign, value)
+	return i.assignVariable(assign, assign.Name, value)
+}
+
+// VisitExprPostfix implements parser.ExprVisitor.
+// The `target++` and `target--` assign the number plus or minus one, the result is the original number.
+func (i *interpreter) VisitExprPostfix(exprPostfix *parser.ExprPostfix) (any, error) {
+	delta := 1.0
+	if exprPostfix.Operator.Type == token.MINUS_MINUS {
+		delta = -1
+	}
+
+	switch target := exprPostfix.Target.(type) {
+	case *parser.ExprVariable:
+		value, err := i.lookupVariable(target.Name, target)
+		if err == nil {
+			err = i.checkNumberOperand(exprPostfix.Operator, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, err = i.assignVariable(target, target.Name, value.(float64)+delta)
+		return value, err
+	case *parser.ExprGet:
+		instance, err := i.evaluate(target.Instance)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := instance.(LoxInstance)
+		if !ok {
+			return i.returnRuntimeError(target.Name, loxerrors.ErrRuntimeOnlyInstancesHaveProperties)
+		}
+		value, err := object.Get(target.Name)
+		if err == nil {
+			value, err = i.invokeGetter(value)
+		}
+		if err == nil {
+			err = i.checkNumberOperand(exprPostfix.Operator, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, err = object.Set(target.Name, value.(float64)+delta)
+		return value, err
+	default:
+		return i.unreachable()
+	}
 }
 
 // VisitBinary implements parser.Visitor.
@@ -328,21 +816,33 @@ func (i *interpreter) VisitExprBinary(expr *parser.ExprBinary) (any, error) {
 
 	switch expr.Operator.Type {
 	case token.GREATER:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l > r, nil
+		}
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
 		return left.(float64) > right.(float64), nil
 	case token.GREATER_EQUAL:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l >= r, nil
+		}
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
 		return left.(float64) >= right.(float64), nil
 	case token.LESS:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l < r, nil
+		}
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
 		return left.(float64) < right.(float64), nil
 	case token.LESS_EQUAL:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l <= r, nil
+		}
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
@@ -367,17 +867,32 @@ func (i *interpreter) VisitExprBinary(expr *parser.ExprBinary) (any, error) {
 				return left + right, nil
 			}
 		}
+		if i.opts.looseStringConcat {
+			_, leftOk := left.(string)
+			_, rightOk := right.(string)
+			if leftOk || rightOk {
+				return i.join("", left, right)
+			}
+		}
 		return i.returnRuntimeError(expr.Operator, loxerrors.ErrRuntimeOperandsMustNumbersOrStrings)
 	case token.SLASH:
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
+		if right.(float64) == 0 && !i.opts.allowInfinity {
+			return i.returnRuntimeError(expr.Operator, loxerrors.ErrRuntimeDivisionByZero)
+		}
 		return left.(float64) / right.(float64), nil
 	case token.STAR:
 		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
 			return nil, err
 		}
 		return left.(float64) * right.(float64), nil
+	case token.STAR_STAR:
+		if err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
+			return nil, err
+		}
+		return math.Pow(left.(float64), right.(float64)), nil
 	}
 
 	return i.unreachable()
@@ -385,12 +900,16 @@ func (i *interpreter) VisitExprBinary(expr *parser.ExprBinary) (any, error) {
 
 // VisitExprFunction implements parser.ExprVisitor.
 func (i *interpreter) VisitExprFunction(exprFunction *parser.ExprFunction) (any, error) {
-	fn := NewLoxFunction(nil, exprFunction, i.Env, false)
+	fn := NewLoxFunction(exprFunction.InferredName, exprFunction, i.Env, false)
 	return fn, nil
 }
 
 // VisitExprCall implements parser.ExprVisitor.
 func (i *interpreter) VisitExprCall(exprCall *parser.ExprCall) (any, error) {
+	if err := i.checkInterrupted(); err != nil {
+		return nil, err
+	}
+
 	callee, err := i.evaluate(exprCall.Callee)
 	if err != nil {
 		return nil, err
@@ -416,8 +935,92 @@ func (i *interpreter) VisitExprCall(exprCall *parser.ExprCall) (any, error) {
 				len(args),
 			))
 	}
+	if fn := variadicFunction(callable); fn != nil && len(args) < fn.requiredArity() {
+		return i.returnRuntimeError(exprCall.CloseParen,
+			loxerrors.ErrRuntimeCalleeMinArityError(
+				fn.requiredArity(),
+				len(args),
+			))
+	}
+
+	name, traced := frameName(callable)
+	if !traced {
+		value, err := callable.Call(i, args)
+		if err != nil {
+			err = i.nativeError(exprCall.CloseParen, err)
+		}
+		return value, err
+	}
+
+	i.frames = append(i.frames, callFrame{function: name, callSite: exprCall.CloseParen})
+	value, err := callable.Call(i, args)
+	if err != nil {
+		i.captureStackTrace(err)
+	}
+	i.frames = i.frames[:len(i.frames)-1]
+	return value, err
+}
+
+// variadicFunction returns the Lox function with the rest parameter invoked by the callable, if any.
+func variadicFunction(callable Callable) *LoxFunction {
+	switch callable := callable.(type) {
+	case *LoxFunction:
+		if callable.Fn.IsVariadic {
+			return callable
+		}
+	case *LoxClass:
+		if init := callable.FindInit(); init != nil && init.Fn.IsVariadic {
+			return init
+		}
+	}
+	return nil
+}
+
+// frameName returns the stack trace name of the callable, natives are not traced.
+func frameName(callable Callable) (string, bool) {
+	switch callable := callable.(type) {
+	case *LoxFunction:
+		if callable.Name == nil {
+			return "#anon", true
+		}
+		return callable.Name.Lexeme, true
+	case *LoxClass:
+		return callable.Name, true
+	default:
+		return "", false
+	}
+}
+
+// captureStackTrace records the active call frames on the runtime error raised in the innermost one.
+func (i *interpreter) captureStackTrace(err error) {
+	var runtimeErr *loxerrors.RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.StackTrace() != nil {
+		return
+	}
+	trace := make([]loxerrors.StackFrame, 0, len(i.frames)+1)
+	tok := runtimeErr.Token()
+	for index := len(i.frames) - 1; index >= 0; index-- {
+		trace = append(trace, loxerrors.StackFrame{Function: i.frames[index].function, Tok: tok})
+		tok = i.frames[index].callSite
+	}
+	trace = append(trace, loxerrors.StackFrame{Tok: tok})
+	runtimeErr.SetStackTrace(trace)
+}
 
-	return callable.Call(i, args)
+// nativeError reports the native function failure at the call site.
+func (i *interpreter) nativeError(tok *token.Token, err error) error {
+	var runtimeErr *loxerrors.RuntimeError
+	var exitErr *ExitError
+	var hookErr *StepHookError
+	if errors.As(err, &runtimeErr) ||
+		errors.As(err, &exitErr) ||
+		errors.As(err, &hookErr) ||
+		errors.Is(err, loxerrors.ErrRuntimeTimeout) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return i.runtimeError(tok, err)
 }
 
 // VisitGrouping implements parser.Visitor.
@@ -437,6 +1040,8 @@ func (i *interpreter) VisitExprLogical(exprLogical *parser.ExprLogical) (any, er
 		return i.evalLogicalAnd(exprLogical.Left, exprLogical.Right)
 	case token.OR:
 		return i.evalLogicalOr(exprLogical.Left, exprLogical.Right)
+	case token.XOR:
+		return i.evalLogicalXor(exprLogical.Left, exprLogical.Right)
 	default:
 		return i.unreachable()
 	}
@@ -465,15 +1070,15 @@ func (i *interpreter) VisitExprSet(exprSet *parser.ExprSet) (any, error) {
 
 // VisitExprSuper implements parser.ExprVisitor.
 func (i *interpreter) VisitExprSuper(exprSuper *parser.ExprSuper) (any, error) {
-	var distance int
-	if depth, ok := i.Locals[exprSuper]; !ok {
+	var local Local
+	if resolved, ok := i.Locals[exprSuper]; !ok {
 		return i.unreachable()
 	} else {
-		distance = depth
+		local = resolved
 	}
 
 	var superClass *LoxClass
-	if _superClass, err := i.Env.GetAt(distance, "super"); err != nil {
+	if _superClass, err := i.Env.GetAt(local.Depth, local.Slot, "super"); err != nil {
 		return nil, err
 	} else if _superClass, ok := _superClass.(*LoxClass); !ok {
 		return i.unreachable()
@@ -482,7 +1087,8 @@ func (i *interpreter) VisitExprSuper(exprSuper *parser.ExprSuper) (any, error) {
 	}
 
 	var instance LoxInstance
-	if _instance, err := i.Env.GetAt(distance-1, "this"); err != nil {
+	// `this` is the only variable in the scope nested in the `super` one
+	if _instance, err := i.Env.GetAt(local.Depth-1, 0, "this"); err != nil {
 		return nil, err
 	} else if _instance, ok := _instance.(LoxInstance); !ok {
 		return i.unreachable()
@@ -494,7 +1100,7 @@ func (i *interpreter) VisitExprSuper(exprSuper *parser.ExprSuper) (any, error) {
 	if method == nil {
 		return i.returnRuntimeError(exprSuper.Method, loxerrors.ErrRuntimeUndefinedProperty(exprSuper.Method.Lexeme))
 	}
-	return method.Bind(instance), nil
+	return i.invokeGetter(method.Bind(instance))
 }
 
 // VisitExprThis implements parser.ExprVisitor.
@@ -502,6 +1108,34 @@ func (i *interpreter) VisitExprThis(exprThis *parser.ExprThis) (any, error) {
 	return i.lookupVariable(exprThis.Keyword, exprThis)
 }
 
+// VisitExprTypeCheck implements parser.ExprVisitor.
+func (i *interpreter) VisitExprTypeCheck(exprTypeCheck *parser.ExprTypeCheck) (any, error) {
+	value, err := i.evaluate(exprTypeCheck.Instance)
+	if err != nil {
+		return nil, err
+	}
+	classValue, err := i.evaluate(exprTypeCheck.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	class, ok := classValue.(*LoxClass)
+	if !ok {
+		return i.returnRuntimeError(exprTypeCheck.Keyword, loxerrors.ErrRuntimeTypeCheckOperandMustBeClass)
+	}
+
+	instance, ok := value.(*objectInstance)
+	if !ok {
+		return false, nil
+	}
+	for c := instance.Class; c != nil; c = c.SuperClass {
+		if c == class {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (i *interpreter) evalLogicalAnd(left, right parser.Expr) (any, error) {
 	if leftValue, err := i.evaluate(left); err != nil || !i.isTruthy(leftValue) {
 		return leftValue, err
@@ -518,6 +1152,20 @@ func (i *interpreter) evalLogicalOr(left, right parser.Expr) (any, error) {
 	return i.evaluate(right)
 }
 
+// evalLogicalXor evaluates both operands, there is no short circuit for the exclusive or.
+func (i *interpreter) evalLogicalXor(left, right parser.Expr) (any, error) {
+	leftValue, err := i.evaluate(left)
+	if err != nil {
+		return nil, err
+	}
+	rightValue, err := i.evaluate(right)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.isTruthy(leftValue) != i.isTruthy(rightValue), nil
+}
+
 // VisitUnary implements parser.Visitor.
 func (i *interpreter) VisitExprUnary(expr *parser.ExprUnary) (any, error) {
 	right, err := i.evaluate(expr.Right)
@@ -539,10 +1187,35 @@ func (i *interpreter) VisitExprUnary(expr *parser.ExprUnary) (any, error) {
 }
 
 func (i *interpreter) execute(stmt parser.Stmt) (any, error) {
+	if i.coverage != nil {
+		i.cover(stmt)
+	}
+	if i.opts.stepHook != nil {
+		if err := i.opts.stepHook(stmt); err != nil {
+			return nil, &StepHookError{Err: err}
+		}
+	}
 	value, err := stmt.Accept(i)
 	return value, err
 }
 
+// cover counts the statement line, the blocks are counted by their statements.
+func (i *interpreter) cover(stmt parser.Stmt) {
+	if _, ok := stmt.(*parser.StmtBlock); ok {
+		return
+	}
+	line, ok := i.stmtLines[stmt]
+	if !ok {
+		if tok := firstToken(stmt); tok != nil {
+			line = tok.Line
+		}
+		i.stmtLines[stmt] = line
+	}
+	if line > 0 {
+		i.coverage[line]++
+	}
+}
+
 func (i *interpreter) executeBlock(env *environment, stmt []parser.Stmt) (value any, err error) {
 	oldEnv := i.setEnv(env)
 	defer i.setEnv(oldEnv)
@@ -571,13 +1244,41 @@ func (i *interpreter) isTruthy(value any) bool {
 	return true
 }
 
+// isEqual compares values without any type coercion,
+// values of different types are never equal.
+// The numbers follow the IEEE semantics: NaN never equals NaN, 0 equals -0.
 func (i *interpreter) isEqual(left, right any) bool {
 	if left == nil && right == nil {
 		return true
 	}
+	if l, ok := left.(float64); ok {
+		r, ok := right.(float64)
+		return ok && l == r
+	}
 	return left == right
 }
 
+// valuesEqual compares the instance with the user defined equals(other) method, if any, falls back to isEqual.
+func (i *interpreter) valuesEqual(left, right any) (bool, error) {
+	if instance, ok := left.(*objectInstance); ok {
+		if method := instance.Class.FindMethod("equals"); method != nil && method.Arity() == 1 {
+			value, err := method.Bind(instance).Call(i, []any{right})
+			if err != nil {
+				return false, err
+			}
+			return i.isTruthy(value), nil
+		}
+	}
+	return i.isEqual(left, right), nil
+}
+
+// stringOperands returns the operands if both are strings, compared lexicographically.
+func stringOperands(left, right any) (string, string, bool) {
+	l, lok := left.(string)
+	r, rok := right.(string)
+	return l, r, lok && rok
+}
+
 func (i *interpreter) checkNumberOperands(tok *token.Token, left, right any) error {
 	if _, ok := left.(float64); !ok {
 		return i.runtimeError(tok, loxerrors.ErrRuntimeOperandsMustBeNumbers)
@@ -603,24 +1304,29 @@ func (i *interpreter) runtimeError(tok *token.Token, err error) error {
 	return loxerrors.NewRuntimeError(tok, err)
 }
 
-func (i *interpreter) resolve(expr parser.Expr, depth int) {
-	i.Locals[expr] = depth
+func (i *interpreter) resolve(expr parser.Expr, local Local) {
+	i.Locals[expr] = local
 }
 
 func (i *interpreter) lookupVariable(name *token.Token, expr parser.Expr) (any, error) {
-	if distance, ok := i.Locals[expr]; ok {
-		return i.Env.GetAt(distance, name.Lexeme)
+	if local, ok := i.Locals[expr]; ok {
+		return i.Env.GetAt(local.Depth, local.Slot, name.Lexeme)
 	}
 
-	return i.Globals.Get(name)
+	value, err := i.Globals.Get(name)
+	if err != nil && i.opts.undefinedAsNil && errors.Is(err, loxerrors.ErrRuntimeUndefinedVariable) {
+		return nil, nil
+	}
+	return value, err
 }
 
-func (i *interpreter) assignVariable(expr *parser.ExprAssign, value any) (any, error) {
-	if distance, ok := i.Locals[expr]; ok {
-		return i.Env.AssignAt(distance, expr.Name, value)
+// assignVariable assigns the variable the expression (e.g. ExprAssign) resolved to.
+func (i *interpreter) assignVariable(expr parser.Expr, name *token.Token, value any) (any, error) {
+	if local, ok := i.Locals[expr]; ok {
+		return i.Env.AssignAt(local.Depth, local.Slot, name, value)
 	}
 
-	return value, i.Globals.Assign(expr.Name, value)
+	return value, i.Globals.Assign(name, value)
 }
 
 func (i *interpreter) setEnv(env *environment) *environment {
@@ -629,6 +1335,45 @@ func (i *interpreter) setEnv(env *environment) *environment {
 	return oldEnv
 }
 
+// readLine reads the next line from Stdin without the line terminator, ok=false at EOF.
+func (i *interpreter) readLine() (line string, ok bool, err error) {
+	if i.stdinLines == nil {
+		i.stdinLines = bufio.NewReader(i.Stdin)
+	}
+	line, err = i.stdinLines.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		if line == "" {
+			return "", false, nil
+		}
+		err = nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, true, nil
+}
+
+func (i *interpreter) setContext(ctx context.Context) context.Context {
+	oldCtx := i.ctx
+	i.ctx = ctx
+	return oldCtx
+}
+
+// checkInterrupted returns an error once the execution deadline has passed, or the context error once it is canceled.
+func (i *interpreter) checkInterrupted() error {
+	select {
+	case <-i.ctx.Done():
+		if errors.Is(i.ctx.Err(), context.DeadlineExceeded) {
+			return loxerrors.ErrRuntimeTimeout
+		}
+		return i.ctx.Err()
+	default:
+		return nil
+	}
+}
+
 func (i *interpreter) unreachable() (any, error) {
 	panic("unreachable")
 }
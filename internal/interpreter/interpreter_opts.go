@@ -2,9 +2,12 @@ package interpreter
 
 import (
 	"io"
+	"maps"
 	"os"
+	"time"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
 )
 
 type interpreterOpts struct {
@@ -13,6 +16,35 @@ type interpreterOpts struct {
 	stdout   io.Writer
 	stderr   io.Writer
 	reporter loxerrors.ErrReporter
+	timeout  time.Duration
+	// string + anything concatenation
+	looseStringConcat bool
+	// undefined globals read as nil
+	undefinedAsNil bool
+	// globals are not nested on the builtins
+	noBuiltins bool
+	// host functions defined in globals
+	natives []native
+	// host values defined in globals
+	globalValues map[string]any
+	// capabilities granted to the scripts
+	fileAccess bool
+	envAccess  bool
+	// executed lines are recorded
+	coverage bool
+	// called before each statement, the error aborts the execution
+	stepHook func(stmt parser.Stmt) error
+	// stdout is flushed after each print
+	autoFlush bool
+	// random numbers seed, the global source is used if nil
+	randSeed *int64
+	// division by zero yields Inf or NaN instead of the runtime error
+	allowInfinity bool
+}
+
+type native struct {
+	name     string
+	function Callable
 }
 
 var defaultInterpreterOpts = interpreterOpts{
@@ -54,6 +86,124 @@ func WithErrorReporter(r loxerrors.ErrReporter) InterpreterOption {
 	}
 }
 
+// WithTimeout limits the execution time of every Interpret, Evaluate (Eval) call.
+// The interpretation fails with loxerrors.ErrRuntimeTimeout once the deadline passes.
+func WithTimeout(timeout time.Duration) InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.timeout = timeout
+	}
+}
+
+// WithLooseStringConcat enables string concatenation with any other value (e.g. "n=" + 5).
+// Applies only when exactly one operand is a string, the other one is coerced to its display string, as it would be printed.
+// By default both operands must be either numbers or strings. The golox CLI enables it with the -loose-concat flag.
+func WithLooseStringConcat() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.looseStringConcat = true
+	}
+}
+
+// WithUndefinedAsNil makes reading an undefined global variable evaluate to nil.
+// By default it fails with loxerrors.ErrRuntimeUndefinedVariable.
+// Assignment to an undefined variable is still an error.
+func WithUndefinedAsNil() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.undefinedAsNil = true
+	}
+}
+
+// WithoutBuiltins runs the scripts without the builtin native functions (Array, clock, ...),
+// the globals contain only what the embedder defines, e.g. with WithNativeFunction.
+func WithoutBuiltins() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.noBuiltins = true
+	}
+}
+
+// WithNativeFunction defines the host function as a global.
+// The arguments count is checked on call, unless the arity is negative (varargs).
+func WithNativeFunction(name string, arity int, fn func(args ...any) (any, error)) InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.natives = append(opts.natives, native{name: name, function: hostFunction(arity, fn)})
+	}
+}
+
+// WithGlobalValues defines the host values as globals, e.g. the script parameters.
+// The values must be the Lox ones, as the native functions take and return: nil, bool, float64, string, ...
+// Repeated options are merged, the later values win.
+func WithGlobalValues(values map[string]any) InterpreterOption {
+	return func(opts *interpreterOpts) {
+		if opts.globalValues == nil {
+			opts.globalValues = make(map[string]any, len(values))
+		}
+		maps.Copy(opts.globalValues, values)
+	}
+}
+
+// hostFunction wraps the host function as the native Callable, the negative arity is varargs.
+func hostFunction(arity int, fn func(args ...any) (any, error)) Callable {
+	return &nativeFunctionN{
+		arity: max(Arity(arity), ArityVarArgs),
+		fn: func(_ *interpreter, args ...any) (any, error) {
+			return fn(args...)
+		},
+	}
+}
+
+// WithFileAccess grants the scripts access to the file system with read_file(path) and write_file(path, contents),
+// reported by hostInfo(). The file functions are not defined by default.
+func WithFileAccess() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.fileAccess = true
+	}
+}
+
+// WithEnvAccess only advertises the environment access capability to the scripts, with hostInfo().hasEnvAccess.
+// No environment functions are defined, it's up to the embedder to provide them, e.g. with WithNativeFunction.
+func WithEnvAccess() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.envAccess = true
+	}
+}
+
+// WithCoverage records the executed statements lines, see Interpreter.Coverage.
+func WithCoverage() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.coverage = true
+	}
+}
+
+// WithStepHook calls the hook before each statement is executed, e.g. to implement the debugger stepping and breakpoints.
+// The hook error aborts the execution wrapped in StepHookError, it is not caught by the script try/catch.
+func WithStepHook(hook func(stmt parser.Stmt) error) InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.stepHook = hook
+	}
+}
+
+// WithAutoFlush flushes the stdout after each print and pprint, if the writer has the Flush method (e.g. bufio.Writer, http.Flusher).
+func WithAutoFlush() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.autoFlush = true
+	}
+}
+
+// WithRandSeed seeds the random() and random_int(n) numbers, e.g. for the reproducible tests.
+// By default the random numbers are not reproducible.
+func WithRandSeed(seed int64) InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.randSeed = &seed
+	}
+}
+
+// WithAllowInfinity makes the division by zero yield the IEEE Inf or NaN (e.g. 1 / 0 is Infinity),
+// by default it fails with loxerrors.ErrRuntimeDivisionByZero. The golox CLI allows it with the -allow-infinity flag.
+func WithAllowInfinity() InterpreterOption {
+	return func(opts *interpreterOpts) {
+		opts.allowInfinity = true
+	}
+}
+
 func newInterpreterOpts(options ...InterpreterOption) *interpreterOpts {
 	opts := defaultInterpreterOpts
 	for _, opt := range options {
@@ -1,8 +1,15 @@
 package interpreter_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,7 +36,64 @@ func TestInterpret(t *testing.T) {
 		{name: `precedence slash`, in: `1 + 9 / 3;`, eval: `4`},
 		{name: `precedence asterix slash`, in: `1 + 2 * 6 / 4;`, eval: `4`},
 		{name: `grouping nested precedence`, in: `((1 + 2) * 3)/2;`, eval: `4.5`},
+		{name: `print integral number`, in: `print 4;`, eval: `nil`, out: "4\n"},
+		{name: `print fraction number`, in: `print 4.5;`, eval: `nil`, out: "4.5\n"},
+		{name: `print negative zero`, in: `print -0;`, eval: `nil`, out: "-0\n"},
+		{name: `print huge number`, in: `print 1000000 * 1000000 * 1000000 * 1000;`, eval: `nil`, out: "1e+21\n"},
+		{name: `eval large integral number`, in: `123456789 * 1000;`, eval: `123456789000`},
 		{name: `strings`, in: `"a" + "b";`, eval: `"ab"`},
+		{name: `num string`, in: `num("3.14");`, eval: `3.14`},
+		{name: `num trims`, in: `num("  42 ") + 1;`, eval: `43`},
+		{name: `num invalid`, in: `num("abc");`, eval: `nil`},
+		{name: `num empty`, in: `num("");`, eval: `nil`},
+		{name: `num number`, in: `num(2.5);`, eval: `2.5`},
+		{name: `num bool`, in: `num(true);`, err: "Argument must be a number or a string."},
+		{name: `json encode scalars`, in: `pprint(json_encode(nil), json_encode(true), json_encode(1.5), json_encode(10), json_encode("a"));`, eval: `nil`, out: "null true 1.5 10 \"a\"\n"},
+		{name: `json encode nested`, in: `var m = {"a": [1, nil], 2: {"b": false}}; pprint(json_encode(m));`, eval: `nil`, out: "{\"a\":[1,null],\"2\":{\"b\":false}}\n"},
+		{name: `json encode instance`, in: `class P { init(x) { this.y = x; this.x = x; } } pprint(json_encode([P(1)]));`, eval: `nil`, out: "[{\"x\":1,\"y\":1}]\n"},
+		{name: `json encode function`, in: `json_encode([clock]);`, err: "Can't encode function as JSON."},
+		{name: `json encode cyclic`, in: `var a = [1]; a.push(a); json_encode(a);`, err: "Can't encode a cyclic structure as JSON."},
+		{name: `json decode`, in: `var v = json_decode(" [1.5, [true, null], {}] "); pprint(v, type(v.get(2)));`, eval: `nil`, out: "[1.5, [true, nil], {}] map\n"},
+		{name: `json round trip`, in: `var m = {"a": [1, {"b": nil}], "c": "d", 3: true}; var s = json_encode(m); pprint(json_decode(s), json_encode(json_decode(s)) == s);`, eval: `nil`, out: "{a: [1, {b: nil}], c: d, 3: true} true\n"},
+		{name: `json decode malformed`, in: `json_decode("[1,");`, err: "Invalid JSON: unexpected end of JSON input."},
+		{name: `json decode invalid`, in: `json_decode("{1}");`, err: "Invalid JSON: object member name must be a string."},
+		{name: `json decode trailing`, in: `json_decode("1 2");`, err: "Invalid JSON: unexpected data after top-level value."},
+		{name: `json decode not string`, in: `json_decode(1);`, err: "Argument must be a string."},
+		{name: `str number`, in: `str(42);`, eval: `"42"`},
+		{name: `str bool`, in: `str(true);`, eval: `"true"`},
+		{name: `str nil`, in: `str(nil);`, eval: `"nil"`},
+		{name: `str string`, in: `str("s");`, eval: `"s"`},
+		{name: `str concat`, in: `str(42) + " items";`, eval: `"42 items"`},
+		{name: `str array`, in: `str([1, 2.5]);`, eval: `"[1, 2.5]"`},
+		{name: `str instance`, in: `class A {} str(A());`, eval: `"A instance"`},
+		{name: `str instance toString`, in: `class A { toString() { return "a"; } } str(A());`, eval: `"a"`},
+		{name: `sleep zero`, in: `sleep(0);`, eval: `nil`},
+		{name: `sleep fraction`, in: `sleep(0.001);`, eval: `nil`},
+		{name: `sleep negative`, in: `sleep(-1);`, err: "Argument must be a non-negative number."},
+		{name: `sleep not a number`, in: `sleep("1");`, err: "Argument must be a non-negative number."},
+		{name: `string less`, in: `"apple" < "banana";`, eval: `true`},
+		{name: `negative zero equality`, in: `0 == -0;`, eval: `true`},
+		{name: `number not equal string`, in: `1 == "1";`, eval: `false`},
+		{name: `string greater equal`, in: `"b" >= "b";`, eval: `true`},
+		{name: `string greater`, in: `"a" > "b";`, eval: `false`},
+		{name: `string less equal prefix`, in: `"ab" <= "a";`, eval: `false`},
+		{name: `string number comparison`, in: `"a" < 1;`, err: "Operands must be numbers."},
+		{name: `map insertion order`, in: `var m = Map(); m.set("b", 1); m.set("a", 2); m.set(3, true); m.set("b", 4); pprint(m);`, eval: `nil`, out: "{b: 4, a: 2, 3: true}\n"},
+		{name: `map get`, in: `var m = Map(); m.set("a", 1); m.get("a") + 1;`, eval: `2`},
+		{name: `map get missing`, in: `Map().get("a");`, eval: `nil`},
+		{name: `map size`, in: `var m = Map(); m.set("a", 1); m.set(2, "b"); m.size;`, eval: `2`},
+		{name: `map size overwrite`, in: `var m = Map(); m.set("a", 1); m.set("a", 2); m.size;`, eval: `1`},
+		{name: `map has`, in: `var m = Map(); m.set("a", nil); pprint(m.has("a"), m.has("b"), m.has(1));`, eval: `nil`, out: "true false false\n"},
+		{name: `map delete`, in: `var m = Map(); m.set("a", 1); m.set("b", 2); pprint(m.delete("a"), m.delete("a"), m.size, m);`, eval: `nil`, out: "true false 1 {b: 2}\n"},
+		{name: `map keys`, in: `var m = Map(); m.set("b", 1); m.set(1, 2); m.set("a", 3); m.keys();`, eval: `[b, 1, a]`},
+		{name: `map keys copy`, in: `var m = Map(); m.set("a", 1); m.keys().push("x"); m.size;`, eval: `1`},
+		{name: `map set property`, in: `Map().size = 1;`, err: "Can't set properties on maps."},
+		{name: `map literal get`, in: `var m = {"a": 1, "b": 2}; m.get("b");`, eval: `2`},
+		{name: `map literal empty`, in: `var m = {}; m.size;`, eval: `0`},
+		{name: `map literal trailing comma`, in: `var m = {1: "one", 2: "two",}; m.size;`, eval: `2`},
+		{name: `map literal expressions`, in: `var k = "a"; var m = {k + "b": 1 + 2, "n": {}}; pprint(m);`, eval: `nil`, out: "{ab: 3, n: {}}\n"},
+		{name: `map literal invalid key`, in: `var m = {nil: 1};`, err: "Invalid map key, must be number or string.\n[line 1] in script (col 9)"},
+		{name: `map invalid key`, in: `Map().set(nil, 1);`, err: "Invalid map key, must be number or string."},
 		{name: `boolean t`, in: `true;`, eval: `true`},
 		{name: `boolean f`, in: `false;`, eval: `false`},
 		{name: `bang`, in: `!false;`, eval: `true`},
@@ -42,6 +106,13 @@ func TestInterpret(t *testing.T) {
 		{name: `bangeq number`, in: `1 != 2;`, eval: `true`},
 		{name: `bangeq string`, in: `"a" != "a";`, eval: `false`},
 		{name: `bangeq string`, in: `"a" != "b";`, eval: `true`},
+		{name: `eqeq number string`, in: `1 == "1";`, eval: `false`},
+		{name: `eqeq nil false`, in: `nil == false;`, eval: `false`},
+		{name: `eqeq true number`, in: `true == 1;`, eval: `false`},
+		{name: `eqeq zero false`, in: `0 == false;`, eval: `false`},
+		{name: `eqeq empty string nil`, in: `"" == nil;`, eval: `false`},
+		{name: `bangeq number string`, in: `1 != "1";`, eval: `true`},
+		{name: `bangeq nil false`, in: `nil != false;`, eval: `true`},
 		{name: `lt number`, in: `1 < 2;`, eval: `true`},
 		{name: `lt number`, in: `1 < 1;`, eval: `false`},
 		{name: `lte number`, in: `2 <= 1;`, eval: `false`},
@@ -75,23 +146,105 @@ func TestInterpret(t *testing.T) {
 		{name: `logic or 2`, in: `nil or 1;`, eval: `1`},
 		{name: `logic or 3`, in: `1 or nil;`, eval: `1`},
 		{name: `logic or short circuit`, in: `1 or Unknown;`, eval: `1`},
+		{name: `const read`, in: `const a = 1; a + 1;`, eval: `2`},
+		{name: `const assign`, in: `const a = 1; a = 2;`, err: "Cannot assign to constant 'a'.\n[line 1] in script (col 14)"},
+		{name: `const local assign`, in: `{ const a = 1; a = a + 1; }`, err: "Cannot assign to constant 'a'.\n[line 1] in script (col 16)"},
+		{name: `const closure assign`, in: `fun f() { const a = 1; fun g() { a = a + 1; } g(); } f();`, err: "Cannot assign to constant 'a'.\n[line 1] in g (col 34)\n[line 1] in f (col 49)\n[line 1] in script (col 56)"},
+		{name: `const closure read`, in: `fun f() { const a = "a"; return fun () => a; } f()();`, eval: `"a"`},
+		{name: `const shadowed`, in: `const a = 1; { var a = 2; a = 3; print a; } a;`, eval: `1`, out: "3\n"},
+		{name: `const global redefined`, in: `const a = 1; var a = 2; a = 3; a;`, eval: `3`},
+		{name: `const duplicate local`, in: `{ const a = 1; const a = 2; print a; }`, err: `Already a variable with this name in this scope.`},
+		{name: `const without initializer`, in: `const a;`, err: `Parse error.`, out: "ERR [line 1] Error at 'a' (col 7): Expect '=' after constant name.\n"},
+		{name: `power`, in: `2 ** 10;`, eval: `1024`},
+		{name: `power right associative`, in: `2 ** 3 ** 2;`, eval: `512`},
+		{name: `power precedence`, in: `2 * 3 ** 2;`, eval: `18`},
+		{name: `power negative base`, in: `-2 ** 2;`, eval: `-4`},
+		{name: `power negative exponent`, in: `2 ** -1;`, eval: `0.5`},
+		{name: `power grouped base`, in: `(-2) ** 2;`, eval: `4`},
+		{name: `power call operands`, in: `fun two() { return 2; } two() ** two();`, eval: `4`},
+		{name: `power numbers only`, in: `"2" ** 2;`, err: "Operands must be numbers.\n[line 1] in script (col 5)"},
+		{name: `postfix increment`, in: `var i=1; i++; i;`, eval: `2`},
+		{name: `postfix decrement`, in: `var i=1; i--; i--; i;`, eval: `-1`},
+		{name: `postfix yields original`, in: `var i=1; var j = i++; [i, j];`, eval: `[2, 1]`},
+		{name: `postfix local`, in: `fun f() { var i = 0; for (var n = 0; n < 3; n++) i++; return i; } f();`, eval: `3`},
+		{name: `postfix property`, in: `class C { var n = 5; } var c = C(); print c.n--; c.n;`, eval: `4`, out: "5\n"},
+		{name: `postfix not a number`, in: `var s = "a"; s++;`, err: "Operand must be a number.\n[line 1] in script (col 15)"},
+		{name: `postfix const`, in: `const i = 1; i++;`, err: "Cannot assign to constant 'i'."},
+		{name: `postfix invalid target`, in: `1++;`, err: `Parse error.`},
+		{name: `postfix double negation`, in: `var i = 3; --i;`, eval: `3`},
+		{name: `postfix then minus`, in: `var i = 3; [i-- * 2, i];`, eval: `[6, 2]`},
+		{name: `postfix then binary minus`, in: `var i = 5; print i-- - 1; print i++ + 1; i;`, eval: `5`, out: "4\n5\n"},
+		{name: `minus negated number`, in: `print 5--3;`, eval: `nil`, out: "8\n"},
+		{name: `minus negated operand`, in: `var a = 2; print a--1; a;`, eval: `2`, out: "3\n"},
+		{name: `minus double negated operand`, in: `var a = 3; a-- - 1;`, eval: `2`},
+		{name: `logic xor`, in: `true xor false;`, eval: `true`},
+		{name: `logic xor both true`, in: `true xor true;`, eval: `false`},
+		{name: `logic xor truthiness`, in: `nil xor "";`, eval: `true`},
+		{name: `logic xor precedence`, in: `true xor true and false;`, eval: `true`},
+		{name: `logic xor evaluates both`, in: `fun t() { print "t"; return true; } t() xor t();`, eval: `false`, out: "t\nt\n"},
+		{name: `logic xor error`, in: `true xor Unknown;`, err: "Undefined variable 'Unknown'."},
 		{name: `while loop`, in: `var a=1;while(a<10){print a;a=a+1;}`, eval: `nil`, out: "1\n2\n3\n4\n5\n6\n7\n8\n9\n"},
 		{name: `for loop`, in: `for(var a=1;a<10;a=a+1){print a;}`, eval: `nil`, out: "1\n2\n3\n4\n5\n6\n7\n8\n9\n"},
 		{name: `break invalid syntax`, in: `break;1;`, err: `Parse error.`, out: `parse error at ';': must be inside a loop to use 'break'`},
 		{name: `continue invalid syntax`, in: `continue;1;`, err: `Parse error.`, out: `parse error at ';': must be inside a loop to use 'continue'`},
 		{name: `for loop`, in: `for(var a=1;a<10;a=a+1){print a;}`, eval: `nil`, out: "1\n2\n3\n4\n5\n6\n7\n8\n9\n"},
+		{name: `empty statements`, in: `;;; print 1;`, eval: `nil`, out: "1\n"},
+		{name: `empty while body`, in: `var c = 3; while ((c = c - 1) > 0); c;`, eval: `0`},
+		{name: `empty for body`, in: `var n = 0; for (var i = 0; i < 3; i = i + 1) n = n + 1;; for (;n > 0; n = n - 1); n;`, eval: `0`},
+		{name: `empty if branches`, in: `if (true); else print 1; print 2;`, eval: `nil`, out: "2\n"},
 		{name: `while break`, in: `var a=0;while(true){if(a>3)break;a=a+1;print a;}`, eval: `nil`, out: "1\n2\n3\n4\n"},
 		{name: `for break`, in: `for(var a=0;a<10;a=a+1){if(a>3)break;print a;}`, eval: `nil`, out: "0\n1\n2\n3\n"},
 		{name: `while continue`, in: `var a=0;while(a<10){a=a+1;if(a<5)continue;print a;}`, eval: `nil`, out: "5\n6\n7\n8\n9\n10\n"},
 		{name: `for continue`, in: `for(var a=0;a<10;a=a+1){if(a<5)continue;print a;}`, eval: `nil`, out: "5\n6\n7\n8\n9\n"},
+		{name: `labeled break outer`, in: `outer: for(var a=0;a<3;a=a+1){var b=0;while(true){if(a==1)break outer;b=b+1;if(b>1)break;print a+b;}}print "done";`, eval: `nil`, out: "1\ndone\n"},
+		{name: `labeled continue outer`, in: `outer: for(var a=0;a<3;a=a+1){for(var b=0;b<3;b=b+1){if(b==1)continue outer;print a*10+b;}}`, eval: `nil`, out: "0\n10\n20\n"},
+		{name: `labeled foreach`, in: `rows: for(var r in [[1,2],[3,4]]){for(var x in r){if(x==2)continue rows;if(x==4)break rows;print x;}}`, eval: `nil`, out: "1\n3\n"},
+		{name: `labeled break innermost`, in: `outer: while(true){inner: while(true){break inner;}print "after";break;}`, eval: `nil`, out: "after\n"},
+		{name: `print many`, in: `print 1, 2, 3;`, eval: `nil`, out: "1 2 3\n"},
+		{name: `print many values`, in: `var a = [1]; print "a:", a, nil, 1 + 1 == 2;`, eval: `nil`, out: "a: [1] nil true\n"},
+		{name: `print many error`, in: `print 1, nil + 1;`, eval: `nil`, err: "Operands must be two numbers or two strings."},
+		{name: `print trailing comma`, in: `print 1,;`, eval: `nil`, err: "Parse error."},
 		{name: `built in pprint`, in: `pprint();`, eval: `nil`, out: "\n"},
 		{name: `built in pprint varargs`, in: `pprint(1,2,nil,3,4);`, eval: `nil`, out: "1 2 nil 3 4\n"},
+		{name: `built in join`, in: `join(", ", 1, 2, 3);`, eval: `"1, 2, 3"`},
+		{name: `built in join values`, in: `join("", "a", nil, [true]) + "!";`, eval: `"anil[true]!"`},
+		{name: `built in join empty`, in: `join("-");`, eval: `""`},
+		{name: `built in join no separator`, in: `join();`, eval: `nil`, err: "Expected at least 1 arguments but got 0."},
+		{name: `built in join separator`, in: `join(1, 2);`, eval: `nil`, err: "Argument must be a string."},
+		{name: `built in ord`, in: `ord("A");`, eval: `65`},
+		{name: `built in ord unicode`, in: `ord("ā");`, eval: `257`},
+		{name: `built in chr`, in: `chr(65);`, eval: `"A"`},
+		{name: `built in chr ord round trip`, in: `chr(ord("ā") + 1);`, eval: `"Ă"`},
+		{name: `built in ord empty`, in: `ord("");`, eval: `nil`, err: "Argument must be a one character string."},
+		{name: `built in ord long`, in: `ord("AB");`, eval: `nil`, err: "Argument must be a one character string."},
+		{name: `built in ord number`, in: `ord(65);`, eval: `nil`, err: "Argument must be a one character string."},
+		{name: `built in chr fraction`, in: `chr(65.5);`, eval: `nil`, err: "Argument must be a valid Unicode code point."},
+		{name: `built in chr negative`, in: `chr(-1);`, eval: `nil`, err: "Argument must be a valid Unicode code point."},
+		{name: `built in chr surrogate`, in: `chr(55296);`, eval: `nil`, err: "Argument must be a valid Unicode code point."},
+		{name: `built in chr too large`, in: `chr(1114112);`, eval: `nil`, err: "Argument must be a valid Unicode code point."},
+		{name: `built in chr string`, in: `chr("A");`, eval: `nil`, err: "Argument must be a valid Unicode code point."},
+		{name: `built in paramNames`, in: `fun f(a, b){ return a + b; } paramNames(f);`, eval: `[a, b]`},
+		{name: `built in paramNames strings`, in: `fun f(a, b){ return a + b; } var n = paramNames(f); n.get(0) == "a" and type(n.get(1)) == "string";`, eval: `true`},
+		{name: `built in paramNames empty`, in: `fun f(){} paramNames(f);`, eval: `[]`},
+		{name: `built in paramNames variadic`, in: `paramNames(fun (a, ...rest) => [a, rest]);`, eval: `[a, rest]`},
+		{name: `built in paramNames method`, in: `class P { m(x) { return x; } } paramNames(P().m);`, eval: `[x]`},
+		{name: `built in paramNames native`, in: `paramNames(clock).length;`, eval: `0`},
+		{name: `built in paramNames not function`, in: `paramNames(1);`, eval: `nil`, err: "Argument must be a function."},
 		{name: `built in time`, in: `clock(1,2);`, eval: `nil`, err: "Expected 0 arguments but got 2."},
 		{name: `call non function`, in: `"non function"();`, eval: `nil`, err: "Can only call functions and classes."},
 		{name: `define fun add`, in: `fun add(a,b){return a+b;}add(1,2);`, eval: `3`},
-		{name: `define fun error 1`, in: `fun add(a,b){return a+b;};add(1,2);`, err: "Parse error.", out: "[line 1] parse error at ';': expected expression.\n"},
+		{name: `define fun trailing semicolon`, in: `fun add(a,b){return a+b;};add(1,2);`, eval: `3`},
 		{name: `recursive fun`, in: `fun a(i){if (i==0) return "Exit"; else {print(i);return a(i-1);}} a(3);`, eval: `"Exit"`, out: "3\n2\n1\n"},
 		{name: `anon fun`, in: `var a=fun (i){return i;};a(1);`, eval: `1`},
+		{name: `arrow fun`, in: `var f = fun(x) => x+1; f(4);`, eval: `5`},
+		{name: `arrow fun inline`, in: `fun map(a, fn) { var r = []; for (var x in a) r.push(fn(x)); return r; } map([1, 2, 3], fun (x) => x * 2);`, eval: `[2, 4, 6]`},
+		{name: `arrow fun closure`, in: `var add = fun (a) => fun (b) => a + b; add(1)(2);`, eval: `3`},
+		{name: `arrow fun variadic`, in: `var count = fun (...xs) => xs.length; count(1, 2);`, eval: `2`},
+		{name: `anon fun name`, in: `str(fun () {});`, eval: `"<fn #anon>"`},
+		{name: `anon fun var name`, in: `var f = fun () {}; str(f);`, eval: `"<fn f>"`},
+		{name: `anon fun assign name`, in: `var f; f = fun () {}; str(f);`, eval: `"<fn f>"`},
+		{name: `anon fun field name`, in: `class A {} var a = A(); a.handler = fun () {}; str(a.handler);`, eval: `"<fn handler>"`},
+		{name: `anon fun name is not rebound`, in: `var f = fun () {}; var g = f; str(g);`, eval: `"<fn f>"`},
 		{name: `closures`, in: `var a="global";{fun showA(){pprint(a);}showA();var a="block";showA();print a;}`, eval: `nil`, out: "global\nglobal\nblock\n"},
 		{name: `oop class`, in: `class A{} print A;`, eval: `nil`, out: "A\n"},
 		{name: `oop class method decl`, in: `class A{a(){}}`, eval: `nil`},
@@ -156,8 +309,137 @@ func TestInterpret(t *testing.T) {
 		array.set(1, "new");
 		// "get" returns the element at a given index.
 		print array.get(1); // "new".`,
-			eval: `nil`, out: "[<nil> <nil> <nil>]\n3\nnew\n",
+			eval: `nil`, out: "[nil, nil, nil]\n3\nnew\n",
 		},
+		{name: `array empty`, in: `Array().length;`, eval: `0`},
+		{name: `array push`, in: `var a = Array(); a.push(1); a.push(2); a.push(3); a.length;`, eval: `3`},
+		{name: `array push get`, in: `var a = Array(1); a.push("b"); a.get(1);`, eval: `"b"`},
+		{name: `array pop`, in: `var a = Array(); a.push(1); a.push(2); print a.pop(); a.length;`, eval: `1`, out: "2\n"},
+		{name: `array pop empty`, in: `Array().pop();`, err: "Can't pop from an empty array."},
+		{name: `array append`, in: `var a = Array(); a.push(1); var b = Array(2); b.append(a); b.length;`, eval: `3`},
+		{name: `array append non array`, in: `Array().append(1);`, err: "Can only append arrays."},
+		{name: `array too many args`, in: `Array(1, 2);`, err: "Expected 1 arguments but got 2."},
+		{name: `array literal get`, in: `[1,2,3].get(1);`, eval: `2`},
+		{name: `array literal empty`, in: `[].length;`, eval: `0`},
+		{name: `array literal trailing comma`, in: `[1, 2, 3,].length;`, eval: `3`},
+		{name: `array literal expressions`, in: `var a = 2; var b = [a, a * 2, "s" + "t"]; print b.get(2); b.get(1);`, eval: `4`, out: "st\n"},
+		{name: `array pprint nested`, in: `var a = Array(); a.push([1, 2]); a.push([3]); pprint(a);`, eval: `nil`, out: "[[1, 2], [3]]\n"},
+		{name: `array pprint values`, in: `pprint([nil, true, 1.5, "s", [], Map()]);`, eval: `nil`, out: "[nil, true, 1.5, s, [], {}]\n"},
+		{name: `array pprint cyclic`, in: `var a = [1]; a.push(a); pprint(a);`, eval: `nil`, out: "[1, [...]]\n"},
+		{name: `array eval nested`, in: `[[1, 2], [3]];`, eval: `[[1, 2], [3]]`},
+		{name: `array fill`, in: `Array(3).fill(0);`, eval: `[0, 0, 0]`},
+		{name: `array fill range`, in: `[1, 2, 3, 4].fill(0, 1, 3);`, eval: `[1, 0, 0, 4]`},
+		{name: `array fill empty range`, in: `[1, 2].fill(0, 2, 2);`, eval: `[1, 2]`},
+		{name: `array fill out of range`, in: `[1, 2].fill(0, 1, 3);`, err: "Array index out of range."},
+		{name: `array fill from start`, in: `[1, 2, 3].fill(0, 1);`, eval: `[1, 0, 0]`},
+		{name: `array fill start out of range`, in: `[1, 2].fill(0, 3);`, err: "Array index out of range."},
+		{name: `array fill no value`, in: `[1, 2].fill();`, err: "Expected 1 to 3 arguments but got 0."},
+		{name: `array fill arity`, in: `[1, 2].fill(0, 1, 2, 3);`, err: "Expected 1 to 3 arguments but got 4."},
+		{name: `array indexOf`, in: `[1, "a", nil].indexOf("a");`, eval: `1`},
+		{name: `array indexOf nil`, in: `[1, "a", nil].indexOf(nil);`, eval: `2`},
+		{name: `array indexOf missing`, in: `[1, 2].indexOf(3);`, eval: `-1`},
+		{name: `array contains`, in: `[1, 2].contains(2);`, eval: `true`},
+		{name: `array contains missing`, in: `[].contains(2);`, eval: `false`},
+		{name: `array contains identity`, in: `class P {} var p = P(); [P(), p].indexOf(p);`, eval: `1`},
+		{
+			name: `array contains equals`,
+			in: `class P { init(x) { this.x = x; } equals(other) { return other is P and this.x == other.x; } }
+			var ps = [P(1), P(2)]; pprint(ps.contains(P(2)), ps.indexOf(P(2)), ps.contains(P(3)), ps.contains(2));`,
+			eval: `nil`, out: "true 1 false false\n",
+		},
+		{name: `foreach sum`, in: `var sum = 0; for (var x in [1, 2, 3]) sum = sum + x; sum;`, eval: `6`},
+		{name: `foreach empty`, in: `var n = 0; for (var x in []) { n = x; } n;`, eval: `0`},
+		{name: `foreach break continue`, in: `for (var x in [1, 2, 3, 4]) { if (x == 2) continue; if (x == 4) break; print x; }`, eval: `nil`, out: "1\n3\n"},
+		{name: `foreach closures`, in: `var fs = []; for (var x in [1, 2]) { fun f() { return x; } fs.push(f); } fs.get(0)() + fs.get(1)();`, eval: `3`},
+		{name: `foreach scope`, in: `var x = "outer"; for (var x in [1]) print x; x;`, eval: `"outer"`, out: "1\n"},
+		{name: `foreach not array`, in: `for (var x in "abc") print x;`, err: "Can only iterate over arrays.\n[line 1] in script (col 12)"},
+		{name: `for initializer scope`, in: `{ var i = "outer"; for (var i = 0; i < 1; i = i + 1) print i; print i; } defined("i");`, eval: `false`, out: "0\nouter\n"},
+		{name: `for initializer slot`, in: `{ var a = 1; var b = 2; for (var i = 0; i < 2; i = i + 1) print a + b + i; }`, eval: `nil`, out: "3\n4\n"},
+		{name: `variadic sum`, in: `fun sum(...nums) { var s = 0; for (var n in nums) s = s + n; return s; } sum(1, 2, 3);`, eval: `6`},
+		{name: `variadic no rest args`, in: `fun f(a, ...rest) { return rest.length + a; } f(1);`, eval: `1`},
+		{name: `variadic rest array`, in: `fun f(a, ...rest) { rest.push(a); return rest; } f(1, 2, nil);`, eval: `[2, nil, 1]`},
+		{name: `variadic too few args`, in: `fun f(a, b, ...rest) { return [a, b, rest]; } f(1);`, err: "Expected at least 2 arguments but got 1."},
+		{name: `variadic init`, in: `class A { init(...xs) { this.n = xs.length; } } A(1, 2).n;`, eval: `2`},
+		{name: `variadic method`, in: `class A { m(...xs) { return xs; } } A().m("a");`, eval: `[a]`},
+		{name: `array zero size`, in: `Array(0).length;`, eval: `0`},
+		{name: `array negative size`, in: `Array(-1);`, err: "Invalid array size, must be a non-negative integer number."},
+		{name: `array fractional size`, in: `Array(1.5);`, err: "Invalid array size, must be a non-negative integer number."},
+		{name: `array non number size`, in: `Array("1");`, err: "Invalid array size, must be a non-negative integer number."},
+		{name: `array huge size`, in: `Array(100000000000000);`, err: "Invalid array size, must be a non-negative integer number."},
+		{name: `array int overflow size`, in: `Array(10 ** 300);`, err: "Invalid array size, must be a non-negative integer number."},
+		{name: `array get integral index`, in: `[1, 2, 3].get(1);`, eval: `2`},
+		{name: `array get fractional index`, in: `[1, 2, 3].get(1.5);`, err: "Invalid array index, must be an integer number."},
+		{name: `array set fractional index`, in: `[1, 2, 3].set(0.1, 0);`, err: "Invalid array index, must be an integer number."},
+		{name: `array literal nested`, in: `[[1], [2, 3]].get(1).get(0);`, eval: `2`},
+		{name: `array literal unterminated`, in: `[1, 2;`, err: `Parse error.`},
+		{name: `type nil`, in: `type(nil);`, eval: `"nil"`},
+		{name: `type bool`, in: `type(true);`, eval: `"bool"`},
+		{name: `type number`, in: `type(1);`, eval: `"number"`},
+		{name: `type string`, in: `type("a");`, eval: `"string"`},
+		{name: `type function`, in: `fun f(){} type(f);`, eval: `"function"`},
+		{name: `type anon function`, in: `type(fun(){});`, eval: `"function"`},
+		{name: `type native function`, in: `type(clock);`, eval: `"function"`},
+		{name: `type method`, in: `class A{m(){}} type(A().m);`, eval: `"function"`},
+		{name: `type class`, in: `class A{} type(A);`, eval: `"class"`},
+		{name: `type instance`, in: `class A{} type(A());`, eval: `"instance"`},
+		{name: `type array`, in: `type(Array(1));`, eval: `"array"`},
+		{name: `type array literal`, in: `type([]);`, eval: `"array"`},
+		{name: `defined builtin`, in: `defined("clock");`, eval: `true`},
+		{name: `defined unknown`, in: `defined("nope");`, eval: `false`},
+		{name: `defined global`, in: `var a; defined("a");`, eval: `true`},
+		{name: `defined before declaration`, in: `print defined("a"); var a = 1;`, eval: `nil`, out: "false\n"},
+		{name: `defined function`, in: `fun f() {} defined("f");`, eval: `true`},
+		{name: `defined local`, in: `{ var local = 1; print local; } defined("local");`, eval: `false`, out: "1\n"},
+		{name: `defined not string`, in: `defined(1);`, err: `Argument must be a string.`},
+		{name: `undef global`, in: `var a = 1; print undef("a"); a;`, out: "true\n", err: `Undefined variable 'a'.`},
+		{name: `undef unknown`, in: `undef("nope");`, eval: `false`},
+		{name: `undef twice`, in: `var a = 1; undef("a"); undef("a");`, eval: `false`},
+		{name: `undef then defined`, in: `var a = 1; undef("a"); defined("a");`, eval: `false`},
+		{name: `undef redefine`, in: `var a = 1; undef("a"); var a = 2; a;`, eval: `2`},
+		{name: `undef builtin`, in: `undef("clock"); type(clock);`, eval: `"function"`},
+		{name: `undef shadowed builtin`, in: `clock = 1; print undef("clock"); type(clock);`, eval: `"function"`, out: "true\n"},
+		{name: `undef local`, in: `{ var a = 1; print undef("a"); print a; }`, eval: `nil`, out: "false\n1\n"},
+		{name: `undef not string`, in: `undef(nil);`, err: `Argument must be a string.`},
+		{name: `error token same line assign`, in: `var a=1; b=2; c=3;`, err: "Undefined variable 'b'.\n[line 1] in script (col 10)"},
+		{name: `error token same line read`, in: `var a=1; print a; print c;`, err: "Undefined variable 'c'.\n[line 1] in script (col 25)"},
+		{name: `error token same line operand`, in: `var a=1; var b="b"; a - b;`, err: "Operands must be numbers.\n[line 1] in script (col 23)"},
+		{name: `error token later line`, in: "var a=1;\nvar b=2; b.c;", err: "Only instances have properties.\n[line 2] in script (col 12)"},
+		{name: `init early return`, in: `class P { init(x) { this.x = 0; if (x < 0) return; this.x = x; } } P(-1).x;`, eval: `0`},
+		{name: `init early return completes`, in: `class P { init(x) { this.x = 0; if (x < 0) return; this.x = x; } } P(5).x;`, eval: `5`},
+		{name: `init early return yields this`, in: `class P { init() { return; } } type(P());`, eval: `"instance"`},
+		{name: `init direct call returns this`, in: `class P { init(x) { this.x = x; if (x > 0) return; } } var p = P(1); p.init(2) == p;`, eval: `true`},
+		{name: `is own class`, in: `class A{} A() is A;`, eval: `true`},
+		{name: `is parent class`, in: `class A{} class B < A{} B() is A;`, eval: `true`},
+		{name: `is subclass own class`, in: `class A{} class B < A{} B() is B;`, eval: `true`},
+		{name: `is grandparent class`, in: `class A{} class B < A{} class C < B{} C() is A;`, eval: `true`},
+		{name: `is not subclass`, in: `class A{} class B < A{} A() is B;`, eval: `false`},
+		{name: `is unrelated class`, in: `class A{} class B{} A() is B;`, eval: `false`},
+		{name: `is non instance`, in: `class A{} 1 is A;`, eval: `false`},
+		{name: `is class itself`, in: `class A{} A is A;`, eval: `false`},
+		{name: `is precedence`, in: `class A{} A() is A == true;`, eval: `true`},
+		{name: `is not a class`, in: `class A{} A() is 1;`, err: `Right operand of 'is' must be a class.`},
+		{name: `field default`, in: `class C { var count = 0; } C().count;`, eval: `0`},
+		{name: `field default nil`, in: `class C { var value; } C().value;`, eval: `nil`},
+		{name: `field before init`, in: `class C { var count = 1; init() { this.count = this.count + 1; } } C().count;`, eval: `2`},
+		{name: `field per instance`, in: `class C { var items = []; } var a = C(); var b = C(); a.items.push(1); b.items.length;`, eval: `0`},
+		{name: `field uses this`, in: `class C { var a = 1; var b = this.a + 1; } C().b;`, eval: `2`},
+		{name: `field inherited`, in: `class A { var x = "a"; } class B < A { var y = "b"; } var b = B(); b.x + b.y;`, eval: `"ab"`},
+		{name: `field overridden`, in: `class A { var x = "a"; } class B < A { var x = "b"; } B().x;`, eval: `"b"`},
+		{name: `field closure`, in: `var n = 0; fun next() { n = n + 1; return n; } class C { var id = next(); } C(); C().id;`, eval: `2`},
+		{name: `field error`, in: `class C { var x = -"a"; } C();`, err: `Operand must be a number.`},
+		{name: `getter`, in: `class Rect { init(w, h) { this.w = w; this.h = h; } area { return this.w * this.h; } } var rect = Rect(2, 3); rect.area;`, eval: `6`},
+		{name: `getter reevaluated`, in: `class C { var n = 0; next { this.n = this.n + 1; return this.n; } } var c = C(); c.next; c.next;`, eval: `2`},
+		{name: `getter inherited`, in: `class A { name { return "a"; } } class B < A {} B().name;`, eval: `"a"`},
+		{name: `getter super`, in: `class A { name { return "a"; } } class B < A { name { return super.name + "b"; } } B().name;`, eval: `"ab"`},
+		{name: `getter class`, in: `class A { class name { return "A"; } } A.name;`, eval: `"A"`},
+		{name: `getter side effect`, in: `class A { greet { print "hi"; } } A().greet;`, eval: `nil`, out: "hi\n"},
+		{name: `getter not callable`, in: `class A { one { return 1; } } A().one();`, err: `Can only call functions and classes.`},
+		{name: `super through two levels`, in: `class A { method() { return "A method"; } } class B < A {} class C < B { test() { return super.method(); } } C().test();`, eval: `"A method"`},
+		{name: `super nearest override`, in: `class A { method() { return "A method"; } } class B < A { method() { return "B method"; } } class C < B { method() { return "C method"; } test() { return super.method(); } } C().test();`, eval: `"B method"`},
+		{name: `super chain`, in: `class A { name() { return "A"; } } class B < A { name() { return super.name() + "B"; } } class C < B { name() { return super.name() + "C"; } } C().name();`, eval: `"ABC"`},
+		{name: `super relative to defining class`, in: `class A { say() { return "A"; } } class B < A { say() { return "B>" + super.say(); } } class C < B {} C().say();`, eval: `"B>A"`},
+		{name: `super not relative to runtime class`, in: `class A { say() { return "A"; } } class B < A { viaSuper() { return super.say(); } say() { return "B"; } } class C < B { say() { return "C"; } } C().viaSuper();`, eval: `"A"`},
+		{name: `init return value`, in: `class P { init() { return 1; } }`, err: `Can't return a value from an initializer.`},
 	}
 
 	for _, tc := range testcases {
@@ -236,7 +518,7 @@ func evaluate(script string) (_evalout, _stdout string, _err error) {
 		return "", stdouterr.String(), err
 	}
 
-	svalue, err := eval.Interpret(stmts)
+	svalue, err := eval.Interpret(context.Background(), stmts)
 	return svalue, stdouterr.String(), err
 }
 
@@ -272,7 +554,7 @@ func replLineByLine(script ...string) (_evalout []string, _out string, _err erro
 			return nil, stdouterr.String(), err
 		}
 
-		svalue, err := eval.Interpret(stmts)
+		svalue, err := eval.Interpret(context.Background(), stmts)
 		if err != nil {
 			return nil, stdouterr.String(), err
 		}
@@ -281,3 +563,905 @@ func replLineByLine(script ...string) (_evalout []string, _out string, _err erro
 
 	return results, stdouterr.String(), nil
 }
+
+func TestEvalExpr(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		in       string
+		value    any
+		reported string
+	}{
+		{name: `number`, in: `1+2`, value: float64(3)},
+		{name: `trailing semicolon`, in: `1 + 2;`, value: float64(3)},
+		{name: `string`, in: `"a" + "b"`, value: "ab"},
+		{name: `bool`, in: `1 < 2`, value: true},
+		{name: `nil`, in: `nil`, value: nil},
+		{name: `statement`, in: `var a = 1;`, reported: "FATAL [line 1] Error at 'var' (col 1): Expect expression.\n"},
+		{name: `two expressions`, in: `1; 2`, reported: "FATAL [line 1] Error at '2' (col 4): Expect end of expression.\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reported := &strings.Builder{}
+			eval := interpreter.NewInterpreter(interpreter.WithErrorReporter(loxerrors.NewErrReporter(reported)))
+			value, err := eval.EvalExpr(tc.in)
+			if tc.reported != "" {
+				require.ErrorIs(t, err, loxerrors.ErrParseError)
+				assert.Equal(t, tc.reported, reported.String())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+
+	// the expression sees the globals defined before
+	eval := interpreter.NewInterpreter()
+	_, err := eval.Eval(`var x = 2;`)
+	require.NoError(t, err)
+	value, err := eval.EvalExpr(`x * 3`)
+	require.NoError(t, err)
+	assert.Equal(t, float64(6), value)
+}
+
+func TestEvalValue(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		in    string
+		value any
+	}{
+		{name: `number`, in: `1+2;`, value: float64(3)},
+		{name: `bool`, in: `var a = 1; a < 2;`, value: true},
+		{name: `string`, in: `"a" + "b";`, value: "ab"},
+		{name: `print`, in: `print 1;`, value: nil},
+		{name: `empty`, in: ``, value: nil},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			eval := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard))
+			tokens, err := scanner.NewScanner(tc.in, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+			require.NoError(t, interpreter.NewResolver(eval, "default").Resolve(stmts))
+
+			value, err := eval.EvalValue(context.Background(), stmts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func TestEvalDetailed(t *testing.T) {
+	t.Parallel()
+
+	result, err := interpreter.EvalDetailed(`print "hello"; 1 + 2;`)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, result.Value)
+	assert.True(t, result.Printable)
+	assert.Equal(t, "hello\n", result.Stdout)
+	assert.Empty(t, result.Diagnostics)
+
+	result, err = interpreter.EvalDetailed(`print "before"; var a = 1;`)
+	require.NoError(t, err)
+	assert.Nil(t, result.Value)
+	assert.False(t, result.Printable)
+	assert.Equal(t, "before\n", result.Stdout)
+
+	result, err = interpreter.EvalDetailed(`print "before"; unknown;`)
+	require.ErrorContains(t, err, "Undefined variable 'unknown'.")
+	assert.Nil(t, result.Value)
+	assert.Equal(t, "before\n", result.Stdout)
+	require.Len(t, result.Diagnostics, 1)
+	require.ErrorIs(t, result.Diagnostics[0], loxerrors.ErrRuntimeUndefinedVariable)
+
+	result, err = interpreter.EvalDetailed(`1 +; 2 +;`)
+	require.ErrorIs(t, err, loxerrors.ErrParseError)
+	assert.Empty(t, result.Stdout)
+	require.Len(t, result.Diagnostics, 2)
+	require.ErrorIs(t, result.Diagnostics[0], loxerrors.ErrParseUnexpectedToken)
+}
+
+func TestInterpretTimeout(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string
+	}{
+		{name: `while loop`, in: `while (true) {}`},
+		{name: `for loop`, in: `for (;;) {}`},
+		{name: `recursion`, in: `fun f(n) { if (n > 0) return f(n - 1); return f(1000); } f(1000);`},
+		{name: `sleep`, in: `sleep(60);`},
+		{name: `sleep overflow`, in: `sleep(10000000000);`},
+		{name: `sleep huge`, in: `sleep(10 ** 300);`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			eval := interpreter.NewInterpreter(interpreter.WithTimeout(50 * time.Millisecond))
+
+			start := time.Now()
+			_, err := eval.Eval(tc.in)
+			require.ErrorIs(t, err, loxerrors.ErrRuntimeTimeout)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+
+	eval := interpreter.NewInterpreter(interpreter.WithTimeout(time.Second))
+	value, err := eval.Eval(`var a = 0; while (a < 10) a = a + 1; a;`)
+	require.NoError(t, err)
+	assert.Equal(t, `10`, value)
+
+	t.Run(`evaluate`, func(t *testing.T) {
+		t.Parallel()
+		eval := interpreter.NewInterpreter(interpreter.WithTimeout(50 * time.Millisecond))
+		reporter := loxerrors.NewErrReporter(io.Discard)
+		tokens, err := scanner.NewScanner(`while (true) {}`, reporter).Scan()
+		require.NoError(t, err)
+		stmts, err := parser.NewParser(tokens, reporter).Parse()
+		require.NoError(t, err)
+		require.NoError(t, interpreter.NewResolver(eval, "default").Resolve(stmts))
+
+		start := time.Now()
+		_, err = eval.Evaluate(context.Background(), stmts[0])
+		require.ErrorIs(t, err, loxerrors.ErrRuntimeTimeout)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestInterpretCancel(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string
+	}{
+		{name: `while loop`, in: `while (true) {}`},
+		{name: `for loop`, in: `for (;;) {}`},
+		{name: `recursion`, in: `fun f(n) { if (n > 0) return f(n - 1); return f(1000); } f(1000);`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			eval := interpreter.NewInterpreter()
+			tokens, err := scanner.NewScanner(tc.in, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+			require.NoError(t, interpreter.NewResolver(eval, "default").Resolve(stmts))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err = eval.Interpret(ctx, stmts)
+			require.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func TestInterpretLocalSlots(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string
+		eval string
+	}{
+		{name: `block locals`, in: `var r; { var a = 1; var b = 2; { var c = a + b; b = c * 10; } r = [a, b]; } r;`, eval: `[1, 30]`},
+		{name: `closure counter`, in: `fun counter() { var n = 0; return fun () { n = n + 1; return n; }; } var c = counter(); c(); c();`, eval: `2`},
+		{name: `params and locals`, in: `fun f(a, b) { var c = a * b; { var d = c + a; return [a, b, c, d]; } } f(2, 3);`, eval: `[2, 3, 6, 8]`},
+		{name: `local class`, in: `fun f() { var x = 1; class A { m() { return [x, A]; } } return A().m(); } f();`, eval: `[1, A]`},
+		{name: `local subclass super`, in: `var r; { class A { m() { return "A"; } } class B < A { m() { return "B" + super.m(); } } r = B().m(); } r;`, eval: `"BA"`},
+		{name: `local fun recursion`, in: `var r; { fun fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } r = fib(10); } r;`, eval: `55`},
+		{name: `foreach closures`, in: `var fs = []; for (var x in [1, 2]) { var y = x * 10; fs.push(fun () => x + y); } [fs.get(0)(), fs.get(1)()];`, eval: `[11, 22]`},
+		{name: `catch variable`, in: `fun f() { var a = 1; try { throw 2; } catch (e) { var b = 3; return a + e + b; } } f();`, eval: `6`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := interpreter.NewInterpreter().Eval(tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.eval, value)
+		})
+	}
+}
+
+func BenchmarkInterpretLocals(b *testing.B) {
+	in := `fun fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); }
+fun loop() { var sum = 0; for (var i = 0; i < 1000; i = i + 1) { var x = i; sum = sum + x; } return sum; }
+fib(15) + loop();`
+
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	tokens, err := scanner.NewScanner(in, reporter).Scan()
+	require.NoError(b, err)
+	stmts, err := parser.NewParser(tokens, reporter).Parse()
+	require.NoError(b, err)
+	eval := interpreter.NewInterpreter()
+	require.NoError(b, interpreter.NewResolver(eval, "default").Resolve(stmts))
+
+	b.ResetTimer()
+	for range b.N {
+		value, err := eval.Interpret(context.Background(), stmts)
+		require.NoError(b, err)
+		require.Equal(b, "500110", value)
+	}
+}
+
+func TestInterpretMethodLookup(t *testing.T) {
+	t.Parallel()
+
+	classes := `class A { m() { return "A"; } n() { return "nA"; } }
+class B < A { m() { return "B" + super.m(); } }
+class C < B { n() { return "nC"; } }
+`
+	testcases := []struct {
+		name string
+		in   string
+		eval string
+		err  string
+	}{
+		{name: `own method`, in: `A().m();`, eval: `"A"`},
+		{name: `override`, in: `B().m();`, eval: `"BA"`},
+		{name: `nearest override`, in: `C().m();`, eval: `"BA"`},
+		{name: `deep override`, in: `[C().n(), B().n(), A().n()];`, eval: `[nC, nA, nA]`},
+		{name: `repeated lookups`, in: `var c = C(); [c.m(), c.n(), c.m(), c.n()];`, eval: `[BA, nC, BA, nC]`},
+		{name: `field shadows method`, in: `var c = C(); c.m = "field"; c.m;`, eval: `"field"`},
+		{name: `missing method`, in: `C().missing();`, err: "Undefined property 'missing'."},
+		{name: `missing method repeated`, in: `var c = C(); c.missing; c.missing;`, err: "Undefined property 'missing'."},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := interpreter.NewInterpreter().Eval(classes + tc.in)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.eval, value)
+		})
+	}
+}
+
+func TestInterpretLooseStringConcat(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string // Input
+		eval string // Expected eval
+		err  string // Expected error in strict mode
+	}{
+		{name: `string number`, in: `"n=" + 5;`, eval: `"n=5"`},
+		{name: `number string`, in: `5 + "=n";`, eval: `"5=n"`},
+		{name: `string fraction`, in: `"n=" + 2.5;`, eval: `"n=2.5"`},
+		{name: `string nil`, in: `"v=" + nil;`, eval: `"v=nil"`},
+		{name: `string bool`, in: `"v=" + true;`, eval: `"v=true"`},
+		{name: `string class`, in: `class A{} "v=" + A;`, eval: `"v=A"`},
+		{name: `string x number`, in: `"x=" + 5;`, eval: `"x=5"`},
+		{name: `number nil`, in: `1 + nil;`, err: `Operands must be two numbers or two strings.`},
+		{name: `bool nil`, in: `true + nil;`, err: `Operands must be two numbers or two strings.`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			loose := interpreter.NewInterpreter(interpreter.WithLooseStringConcat())
+			value, err := loose.Eval(tc.in)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.eval, value)
+			}
+
+			strict := interpreter.NewInterpreter()
+			_, err = strict.Eval(tc.in)
+			require.ErrorContains(t, err, `Operands must be two numbers or two strings.`)
+		})
+	}
+}
+
+func TestInterpretToString(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string // Input
+		eval string // Expected eval
+		out  string // Expected output
+		err  string // Expected error
+	}{
+		{name: `print`, in: `class Foo { toString() { return "custom"; } } var inst = Foo(); print inst;`, eval: `nil`, out: "custom\n"},
+		{name: `pprint`, in: `class Foo { toString() { return "custom"; } } pprint(1, Foo());`, eval: `nil`, out: "1 custom\n"},
+		{name: `repl result`, in: `class Foo { toString() { return "custom"; } } Foo();`, eval: `custom`},
+		{name: `inherited`, in: `class Foo { toString() { return "foo"; } } class Bar < Foo {} print Bar();`, eval: `nil`, out: "foo\n"},
+		{name: `fields`, in: `class P { init(x) { this.x = x; } toString() { return "P(" + this.x + ")"; } } print P("a");`, eval: `nil`, out: "P(a)\n"},
+		{name: `class method`, in: `class Foo { class toString() { return "Foo class"; } } print Foo;`, eval: `nil`, out: "Foo class\n"},
+		{name: `no method`, in: `class Foo {} print Foo();`, eval: `nil`, out: "Foo instance\n"},
+		{name: `non string`, in: `class Foo { toString() { return 1; } } print Foo();`, eval: `nil`, out: "Foo instance\n"},
+		{name: `with arguments`, in: `class Foo { toString(a) { return a; } } print Foo();`, eval: `nil`, out: "Foo instance\n"},
+		{name: `error`, in: `class Foo { toString() { return nope; } } print Foo(); print "after";`, err: "Undefined variable 'nope'."},
+		{name: `error in array`, in: `class Foo { toString() { return nope; } } print [1, Foo()];`, err: "Undefined variable 'nope'."},
+		{name: `error in str`, in: `class Foo { toString() { throw "boom"; } } str(Foo());`, err: "boom"},
+		{name: `error caught`, in: `class Foo { toString() { throw "boom"; } } try { print Foo(); } catch (e) { print e; }`, eval: `nil`, out: "boom\n"},
+		{name: `recursion`, in: `class Foo { toString() { print this; return "outer"; } } print Foo();`, eval: `nil`, out: "Foo instance\nouter\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			evalout, stdout, err := evaluate(tc.in)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				assert.Equal(t, tc.out, stdout)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.eval, evalout)
+			assert.Equal(t, tc.out, stdout)
+		})
+	}
+}
+
+func TestInterpretTryCatch(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string // Input
+		out  string // Expected output
+		err  string // Expected error
+	}{
+		{name: `throw string caught`, in: `try { throw "boom"; } catch (e) { print e; }`, out: "boom\n"},
+		{name: `finally on success`, in: `try { print "ok"; } catch (e) { print e; } finally { print "finally"; }`, out: "ok\nfinally\n"},
+		{name: `finally on error`, in: `try { throw "boom"; } catch (e) { print e; } finally { print "finally"; }`, out: "boom\nfinally\n"},
+		{name: `finally without catch`, in: `try { throw "boom"; } finally { print "finally"; }`, out: "finally\n", err: "boom"},
+		{name: `statements after throw skipped`, in: `try { throw 1; print "skipped"; } catch (e) { print e; }`, out: "1\n"},
+		{name: `thrown instance`, in: `class E { init(m) { this.m = m; } } try { throw E("bad"); } catch (e) { print e is E; print e.m; }`, out: "true\nbad\n"},
+		{name: `throw from function`, in: `fun f() { throw "deep"; } try { f(); } catch (e) { print e; }`, out: "deep\n"},
+		{name: `runtime error caught`, in: `try { -"a"; } catch (e) { print e; }`, out: "Operand must be a number.\n"},
+		{name: `rethrow`, in: `try { try { throw "inner"; } catch (e) { throw e + "!"; } } catch (e) { print e; }`, out: "inner!\n"},
+		{name: `uncaught`, in: `throw "boom";`, err: "boom"},
+		{name: `error in catch`, in: `try { throw 1; } catch (e) { throw e + 1; } finally { print "finally"; }`, out: "finally\n", err: "2"},
+		{name: `return through finally`, in: `fun f() { try { return "try"; } finally { print "finally"; } } print f();`, out: "finally\ntry\n"},
+		{name: `break through finally`, in: `while (true) { try { break; } finally { print "finally"; } } print "done";`, out: "finally\ndone\n"},
+		{name: `catch scope`, in: `var e = "outer"; try { throw "inner"; } catch (e) { print e; } print e;`, out: "inner\nouter\n"},
+		{name: `missing catch and finally`, in: `try { }`, out: "FATAL [line 1] Error at end (col 8): Expect 'catch' or 'finally' after try block.\n", err: `Parse error.`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, stdout, err := evaluate(tc.in)
+			assert.Equal(t, tc.out, stdout)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInterpretUndefinedAsNil(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name      string
+		in        string // Input
+		eval      string // Expected eval with WithUndefinedAsNil
+		err       string // Expected error with WithUndefinedAsNil
+		errStrict string // Expected error by default
+	}{
+		{name: `undefined`, in: `unknown;`, eval: `nil`, errStrict: `Undefined variable 'unknown'.`},
+		{name: `undefined in expression`, in: `unknown == nil;`, eval: `true`, errStrict: `Undefined variable 'unknown'.`},
+		{name: `undefined in function`, in: `fun f() { return unknown; } f();`, eval: `nil`, errStrict: `Undefined variable 'unknown'.`},
+		{name: `defined later`, in: `fun f() { return later; } var later = 1; f();`, eval: `1`},
+		{name: `undefined after undef`, in: `var a = 1; undef("a"); a;`, eval: `nil`, errStrict: `Undefined variable 'a'.`},
+		{name: `assign undefined`, in: `unknown = 1;`, err: `Undefined variable 'unknown'.`, errStrict: `Undefined variable 'unknown'.`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := interpreter.NewInterpreter(interpreter.WithUndefinedAsNil()).Eval(tc.in)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.eval, value)
+			}
+
+			value, err = interpreter.NewInterpreter().Eval(tc.in)
+			if tc.errStrict != "" {
+				require.ErrorContains(t, err, tc.errStrict)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.eval, value)
+			}
+		})
+	}
+}
+
+func TestInterpretWithoutBuiltins(t *testing.T) {
+	t.Parallel()
+
+	double := interpreter.WithNativeFunction("double", 1, func(args ...any) (any, error) {
+		if n, ok := args[0].(float64); ok {
+			return n * 2, nil
+		}
+		return nil, errors.New("Argument must be a number.")
+	})
+
+	sandbox := interpreter.NewInterpreter(interpreter.WithoutBuiltins(), double)
+	_, err := sandbox.Eval(`clock();`)
+	require.ErrorContains(t, err, "Undefined variable 'clock'.")
+	_, err = sandbox.Eval(`Array(1);`)
+	require.ErrorContains(t, err, "Undefined variable 'Array'.")
+	value, err := sandbox.Eval(`double(21);`)
+	require.NoError(t, err)
+	assert.Equal(t, "42", value)
+	_, err = sandbox.Eval(`double(1, 2);`)
+	require.ErrorContains(t, err, "Expected 1 arguments but got 2.")
+	_, err = sandbox.Eval(`double("a");`)
+	require.ErrorContains(t, err, "Argument must be a number.")
+
+	// builtins are available by default, along with the natives
+	value, err = interpreter.NewInterpreter(double).Eval(`type(clock) + type(double);`)
+	require.NoError(t, err)
+	assert.Equal(t, `"functionfunction"`, value)
+}
+
+func TestInterpretWithGlobalValues(t *testing.T) {
+	t.Parallel()
+
+	stdout := &strings.Builder{}
+	i := interpreter.NewInterpreter(
+		interpreter.WithStdout(stdout),
+		interpreter.WithGlobalValues(map[string]any{"x": 10.0, "name": "lox"}),
+		interpreter.WithGlobalValues(map[string]any{"name": "golox", "debug": true}),
+	)
+	value, err := i.Eval(`print x; x = x + 1; pprint(name, debug); x;`)
+	require.NoError(t, err)
+	assert.Equal(t, "11", value)
+	assert.Equal(t, "10\ngolox true\n", stdout.String())
+}
+
+func TestInterpretNativeFunctionVarArgs(t *testing.T) {
+	t.Parallel()
+
+	count := interpreter.WithNativeFunction("count", -1, func(args ...any) (any, error) {
+		return float64(len(args)), nil
+	})
+	value, err := interpreter.NewInterpreter(count).Eval(`count() + count(1, 2, 3);`)
+	require.NoError(t, err)
+	assert.Equal(t, "3", value)
+}
+
+func TestInterpretStackTrace(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := evaluate(`fun countdown(n) {
+  if (n == 0) return nil + 1;
+  return countdown(n - 1);
+}
+countdown(2);`)
+	require.Error(t, err)
+	assert.Equal(t, `Operands must be two numbers or two strings.
+[line 2] in countdown (col 26)
+[line 3] in countdown (col 25)
+[line 3] in countdown (col 25)
+[line 5] in script (col 12)`, err.Error())
+
+	_, _, err = evaluate(`fun check(a) { return a is 1; }
+check(nil);`)
+	require.Error(t, err)
+	assert.Equal(t, `Right operand of 'is' must be a class.
+[line 1] in check (col 25)
+[line 2] in script (col 10)`, err.Error())
+
+	_, _, err = evaluate(`fun pop(a) { return a.pop(); }
+pop([]);`)
+	require.Error(t, err)
+	assert.Equal(t, `Can't pop from an empty array.
+[line 1] in pop (col 23)
+[line 2] in script (col 7)`, err.Error())
+
+	_, _, err = evaluate(`var fail = fun () { return -"a"; };
+fail();`)
+	require.Error(t, err)
+	assert.Equal(t, `Operand must be a number.
+[line 1] in fail (col 28)
+[line 2] in script (col 6)`, err.Error())
+}
+
+func TestInterpretNativeErrorIsRuntimeError(t *testing.T) {
+	t.Parallel()
+
+	fail := interpreter.WithNativeFunction("fail", 0, func(args ...any) (any, error) {
+		return nil, errors.New("Native failure.")
+	})
+	_, err := interpreter.NewInterpreter(fail).Eval(`fun f() { return fail(); }
+f();`)
+	var runtimeErr *loxerrors.RuntimeError
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, `Native failure.
+[line 1] in f (col 23)
+[line 2] in script (col 3)`, err.Error())
+}
+
+func TestInterpretExit(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string
+		code int
+	}{
+		{name: `exit`, in: `exit(3);`, code: 3},
+		{name: `zero`, in: `exit(0);`, code: 0},
+		{name: `from function`, in: `fun f() { exit(255); } f();`, code: 255},
+		{name: `not caught`, in: `try { exit(4); } catch (e) { print e; }`, code: 4},
+		{name: `from toString`, in: `class A { toString() { exit(3); } } print A(); print "after";`, code: 3},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard)).Eval(tc.in)
+			var exitErr *interpreter.ExitError
+			require.ErrorAs(t, err, &exitErr)
+			assert.Equal(t, tc.code, exitErr.Code)
+		})
+	}
+
+	for _, in := range []string{`exit(-1);`, `exit(256);`, `exit(1.5);`, `exit("1");`} {
+		_, err := interpreter.NewInterpreter().Eval(in)
+		require.ErrorIs(t, err, loxerrors.ErrRuntimeExitCodeMustBeInteger, in)
+	}
+}
+
+func TestInterpretAssert(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{name: `passes`, in: `assert(1 == 1);`},
+		{name: `passes with message`, in: `assert("a", "nope");`},
+		{name: `fails`, in: `assert(nil);`, err: "Assertion failed.\n[line 1] in script (col 11)"},
+		{name: `fails with message`, in: `assert(false, "nope");`, err: "nope\n[line 1] in script (col 21)"},
+		{name: `no arguments`, in: `assert();`, err: "Expected at least 1 arguments but got 0.\n[line 1] in script (col 8)"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := interpreter.NewInterpreter().Eval(tc.in)
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "nil", value)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestInterpretDivisionByZero(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		in    string
+		allow string // Expected eval with the infinity allowed
+		err   string // Expected error by default
+	}{
+		{name: `infinity`, in: `1 / 0;`, allow: `Infinity`, err: "Division by zero.\n[line 1] in script (col 3)"},
+		{name: `negative infinity`, in: `-1 / 0;`, allow: `-Infinity`, err: "Division by zero.\n[line 1] in script (col 4)"},
+		{name: `nan equality`, in: `(0/0) == (0/0);`, allow: `false`, err: "Division by zero.\n[line 1] in script (col 3)"},
+		{name: `nan inequality`, in: `var nan = 0/0; nan != nan;`, allow: `true`, err: "Division by zero.\n[line 1] in script (col 12)"},
+		{name: `nan not in array`, in: `var nan = 0/0; [nan].contains(nan);`, allow: `false`, err: "Division by zero.\n[line 1] in script (col 12)"},
+		{name: `negative zero divisor`, in: `1 / -0;`, allow: `-Infinity`, err: "Division by zero.\n[line 1] in script (col 3)"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := interpreter.NewInterpreter().Eval(tc.in)
+			require.EqualError(t, err, tc.err)
+			require.ErrorIs(t, err, loxerrors.ErrRuntimeDivisionByZero)
+
+			value, err := interpreter.NewInterpreter(interpreter.WithAllowInfinity()).Eval(tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.allow, value)
+		})
+	}
+}
+
+func TestInterpretRandom(t *testing.T) {
+	t.Parallel()
+
+	in := `var a = Array(); for (var i = 0; i < 5; i = i + 1) { a.push(random()); a.push(random_int(10)); } a;`
+	run := func(options ...interpreter.InterpreterOption) string {
+		value, err := interpreter.NewInterpreter(options...).Eval(in)
+		require.NoError(t, err)
+		return value
+	}
+
+	seeded := run(interpreter.WithRandSeed(42))
+	assert.Equal(t, seeded, run(interpreter.WithRandSeed(42)))
+	assert.NotEqual(t, seeded, run(interpreter.WithRandSeed(7)))
+
+	value, err := interpreter.NewInterpreter().Eval(`var r = random(); var n = random_int(3);
+0 <= r and r < 1 and (n == 0 or n == 1 or n == 2);`)
+	require.NoError(t, err)
+	assert.Equal(t, "true", value)
+
+	value, err = interpreter.NewInterpreter().Eval(`Math.PI + Math.E;`)
+	require.NoError(t, err)
+	assert.Equal(t, "5.859874482048838", value)
+
+	for _, in := range []string{`random_int(0);`, `random_int(1.5);`, `random_int("1");`, `random_int(9223372036854775808);`} {
+		_, err := interpreter.NewInterpreter().Eval(in)
+		require.ErrorIs(t, err, loxerrors.ErrRuntimeArgumentMustBePositiveInteger, in)
+	}
+	_, err = interpreter.NewInterpreter().Eval(`Math.PI = 3;`)
+	require.ErrorIs(t, err, loxerrors.ErrRuntimeMathCantSetProperties)
+}
+
+func TestInterpretCaptureOutput(t *testing.T) {
+	t.Parallel()
+
+	var stdout strings.Builder
+	eval := interpreter.NewInterpreter(interpreter.WithStdout(&stdout))
+
+	value, err := eval.Eval(`fun f() { print 1; pprint(2, 3); } var s = captureOutput(f); s;`)
+	require.NoError(t, err)
+	assert.Equal(t, `"1\n2 3\n"`, value)
+	assert.Empty(t, stdout.String())
+
+	_, err = eval.Eval(`captureOutput(fun () { print "inner"; throw "failed"; });`)
+	require.ErrorContains(t, err, "failed")
+	value, err = eval.Eval(`print "outer"; captureOutput(fun () {});`)
+	require.NoError(t, err)
+	assert.Equal(t, `""`, value)
+	assert.Equal(t, "outer\n", stdout.String())
+
+	_, err = eval.Eval(`captureOutput(fun (a) { return a; });`)
+	require.ErrorIs(t, err, loxerrors.ErrRuntimeArgumentMustBeFunctionWithoutArguments)
+}
+
+// flushWriter records the output written before each flush.
+type flushWriter struct {
+	strings.Builder
+	flushed []string
+}
+
+func (w *flushWriter) Flush() error {
+	w.flushed = append(w.flushed, w.String())
+	return nil
+}
+
+func TestInterpretAutoFlush(t *testing.T) {
+	t.Parallel()
+
+	in := `print 1; pprint(2, 3); var a = 4;`
+
+	w := &flushWriter{}
+	_, err := interpreter.NewInterpreter(interpreter.WithStdout(w), interpreter.WithAutoFlush()).Eval(in)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\n", "1\n2 3\n"}, w.flushed)
+
+	w = &flushWriter{}
+	_, err = interpreter.NewInterpreter(interpreter.WithStdout(w)).Eval(in)
+	require.NoError(t, err)
+	assert.Empty(t, w.flushed)
+	assert.Equal(t, "1\n2 3\n", w.String())
+}
+
+func TestInterpretFileAccess(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data.txt")
+	script := `write_file("` + path + `", "a" + "b"); pprint(read_file("` + path + `"));`
+
+	_, err := interpreter.NewInterpreter().Eval(script)
+	require.ErrorContains(t, err, "Undefined variable 'write_file'.")
+	assert.NoFileExists(t, path)
+
+	stdout := &strings.Builder{}
+	_, err = interpreter.NewInterpreter(interpreter.WithFileAccess(), interpreter.WithStdout(stdout)).Eval(script)
+	require.NoError(t, err)
+	assert.Equal(t, "ab\n", stdout.String())
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", string(contents))
+
+	_, err = interpreter.NewInterpreter(interpreter.WithFileAccess()).Eval(`read_file("` + path + `.missing");`)
+	require.ErrorContains(t, err, "no such file or directory")
+	var runtimeErr *loxerrors.RuntimeError
+	require.ErrorAs(t, err, &runtimeErr)
+
+	_, err = interpreter.NewInterpreter(interpreter.WithFileAccess()).Eval(`write_file("` + path + `", 1);`)
+	require.ErrorContains(t, err, "Argument must be a string.")
+}
+
+func TestInterpretRegisterNativeFunction(t *testing.T) {
+	t.Parallel()
+
+	i := interpreter.NewInterpreter()
+	i.RegisterNativeFunction("double", 1, func(args ...any) (any, error) {
+		if x, ok := args[0].(float64); ok {
+			return x * 2, nil
+		}
+		return nil, loxerrors.ErrRuntimeOperandMustBeNumber
+	})
+
+	value, err := i.Eval(`double(21);`)
+	require.NoError(t, err)
+	assert.Equal(t, "42", value)
+
+	_, err = i.Eval(`double(1, 2);`)
+	require.EqualError(t, err, "Expected 1 arguments but got 2.\n[line 1] in script (col 12)")
+
+	_, err = i.Eval(`double("x");`)
+	require.EqualError(t, err, "Operand must be a number.\n[line 1] in script (col 11)")
+}
+
+func TestInterpretStepHook(t *testing.T) {
+	t.Parallel()
+
+	script := `var n = 0;
+while (true) {
+  try {
+    n = n + 1;
+  } catch (e) {
+    print e;
+  }
+}`
+	errAbort := errors.New("abort")
+	var steps []string
+	hook := func(stmt parser.Stmt) error {
+		if len(steps) == 6 {
+			return errAbort
+		}
+		steps = append(steps, fmt.Sprintf("%T", stmt))
+		return nil
+	}
+
+	stdout := &strings.Builder{}
+	_, err := interpreter.NewInterpreter(interpreter.WithStepHook(hook), interpreter.WithStdout(stdout)).Eval(script)
+	require.ErrorIs(t, err, errAbort)
+	var hookErr *interpreter.StepHookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Empty(t, stdout.String(), "the hook error is not caught")
+	assert.Equal(t, []string{
+		"*parser.StmtVar",
+		"*parser.StmtWhile",
+		"*parser.StmtBlock",
+		"*parser.StmtTry",
+		"*parser.StmtExpression",
+		"*parser.StmtBlock",
+	}, steps)
+}
+
+func TestInterpretStepHookThroughNative(t *testing.T) {
+	t.Parallel()
+
+	script := `print "start";
+fun f() {
+  print "abort";
+}
+try {
+  captureOutput(f);
+} catch (e) {
+  print "caught " + e;
+}
+print "after";`
+	errAbort := errors.New("abort")
+	hook := func(stmt parser.Stmt) error {
+		if stmtPrint, ok := stmt.(*parser.StmtPrint); ok && stmtPrint.Keyword.Line == 3 {
+			return errAbort
+		}
+		return nil
+	}
+
+	stdout := &strings.Builder{}
+	_, err := interpreter.NewInterpreter(interpreter.WithStepHook(hook), interpreter.WithStdout(stdout)).Eval(script)
+	require.ErrorIs(t, err, errAbort)
+	assert.Equal(t, "start\n", stdout.String(), "the hook error is not caught")
+}
+
+func TestInterpretCoverage(t *testing.T) {
+	t.Parallel()
+
+	script := `fun sign(n) {
+  if (n < 0) {
+    return -1;
+  }
+  return 1;
+}
+var s = 0;
+for (var i = 0; i < 3; i = i + 1) {
+  s = s + sign(i);
+}
+print s;`
+
+	stdout := &strings.Builder{}
+	i := interpreter.NewInterpreter(interpreter.WithCoverage(), interpreter.WithStdout(stdout))
+	_, err := i.Eval(script)
+	require.NoError(t, err)
+	assert.Equal(t, "3\n", stdout.String())
+	// the negative branch, line 3, is never taken; the for loop and its initializer are both on line 8
+	assert.Equal(t, map[int]int{1: 1, 2: 3, 5: 3, 7: 1, 8: 2, 9: 3, 11: 1}, i.Coverage())
+	i.Coverage()[3] = 1
+	assert.NotContains(t, i.Coverage(), 3, "the returned coverage is a copy")
+
+	assert.Nil(t, interpreter.NewInterpreter().Coverage())
+}
+
+func TestInterpretHostInfo(t *testing.T) {
+	t.Parallel()
+
+	script := `var info = hostInfo(); pprint(info.version, info.hasFileAccess, info.hasEnvAccess);`
+
+	testcases := []struct {
+		name    string
+		options []interpreter.InterpreterOption
+		out     string
+	}{
+		{name: "default", out: "devel false false\n"},
+		{name: "file", options: []interpreter.InterpreterOption{interpreter.WithFileAccess()}, out: "devel true false\n"},
+		{name: "env", options: []interpreter.InterpreterOption{interpreter.WithEnvAccess()}, out: "devel false true\n"},
+		{name: "all", options: []interpreter.InterpreterOption{interpreter.WithFileAccess(), interpreter.WithEnvAccess()}, out: "devel true true\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout := &strings.Builder{}
+			options := append([]interpreter.InterpreterOption{interpreter.WithStdout(stdout)}, tc.options...)
+			_, err := interpreter.NewInterpreter(options...).Eval(script)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, stdout.String())
+		})
+	}
+}
+
+func TestInterpretReadLine(t *testing.T) {
+	t.Parallel()
+
+	stdout := &strings.Builder{}
+	eval := interpreter.NewInterpreter(
+		interpreter.WithStdin(strings.NewReader("hello\nworld\n")),
+		interpreter.WithStdout(stdout),
+	)
+	_, err := eval.Eval(`print read_line(); print read_line(); print read_line();`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\nworld\nnil\n", stdout.String())
+
+	// the last line may lack the line terminator
+	value, err := interpreter.NewInterpreter(interpreter.WithStdin(strings.NewReader("a\r\nb"))).
+		Eval(`read_line() + read_line();`)
+	require.NoError(t, err)
+	assert.Equal(t, `"ab"`, value)
+}
@@ -0,0 +1,262 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/token"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// The linter rule names.
+const (
+	RuleResolve           = "resolve"
+	RuleUnusedVariable    = "unused-variable"
+	RuleShadowing         = "shadowing"
+	RuleUnreachableCode   = "unreachable-code"
+	RuleDeadBranch        = "dead-branch"
+	RuleUselessExpression = "useless-expression"
+)
+
+const (
+	lintUnreachableCode   = "Unreachable code."
+	lintDeadBranch        = "Constant condition, the branch is never executed."
+	lintUselessExpression = "Expression result is not used."
+)
+
+// Finding is the single linter diagnostic.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Line and Column are the position of the finding, the Column is 0 if unknown.
+	Line, Column int
+	Message      string
+}
+
+// String implements fmt.Stringer.
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s: %s (%s)", token.Position(f.Line, f.Column), f.Severity, f.Message, f.Rule)
+}
+
+// Error implements error, e.g. to report the finding with loxerrors.ErrReporter.
+func (f Finding) Error() string {
+	return f.String()
+}
+
+// Lint resolves the statements with the resolver profile and checks them for the suspicious code.
+// The resolver errors are error findings; the demoted resolver errors and the checks are warnings.
+// The findings are ordered by the position.
+func Lint(stmts []parser.Stmt, profile string) []Finding {
+	l := &linter{}
+
+	result := NewResolver(NewInterpreter(), profile).ResolveDetailed(stmts)
+	for _, err := range result.Errors {
+		l.resolverFinding(err, SeverityError)
+	}
+	for _, err := range result.Warnings {
+		l.resolverFinding(err, SeverityWarning)
+	}
+	for _, err := range result.Notes {
+		l.resolverFinding(err, SeverityWarning)
+	}
+
+	parser.Walk(stmts, l.check)
+	l.checkUnreachable(stmts)
+
+	slices.SortStableFunc(l.findings, func(a, b Finding) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return l.findings
+}
+
+type linter struct {
+	findings []Finding
+}
+
+func (l *linter) resolverFinding(err error, severity Severity) {
+	var parseErr *loxerrors.ParserError
+	if !errors.As(err, &parseErr) {
+		l.findings = append(l.findings, Finding{Rule: RuleResolve, Severity: severity, Message: err.Error()})
+		return
+	}
+
+	rule := RuleResolve
+	switch cause := errors.Unwrap(parseErr); cause {
+	case loxerrors.ErrParseLocalVariableNotUsed:
+		rule = RuleUnusedVariable
+	case loxerrors.ErrParseLocalVariableShadowsOuter:
+		rule = RuleShadowing
+	}
+	l.report(parseErr.Token(), rule, severity, errors.Unwrap(parseErr).Error())
+}
+
+func (l *linter) report(tok *token.Token, rule string, severity Severity, message string) {
+	finding := Finding{Rule: rule, Severity: severity, Message: message}
+	if tok != nil {
+		finding.Line, finding.Column = tok.Line, tok.Column
+	}
+	l.findings = append(l.findings, finding)
+}
+
+// check is the parser.Walk callback, it checks the node and the statements lists it owns.
+func (l *linter) check(node any) bool {
+	switch node := node.(type) {
+	case *parser.StmtBlock:
+		l.checkUnreachable(node.Statements)
+	case *parser.ExprFunction:
+		l.checkUnreachable(node.Body)
+	case *parser.StmtTry:
+		l.checkUnreachable(node.Body)
+		l.checkUnreachable(node.CatchBody)
+		l.checkUnreachable(node.FinallyBody)
+	case *parser.StmtIf:
+		if value, ok := constantCondition(node.Condition); ok && (!value || node.ElseBranch != nil) {
+			l.report(firstToken(node.Condition), RuleDeadBranch, SeverityWarning, lintDeadBranch)
+		}
+	case *parser.StmtWhile:
+		if value, ok := constantCondition(node.Condition); ok && !value {
+			l.report(firstToken(node.Condition), RuleDeadBranch, SeverityWarning, lintDeadBranch)
+		}
+	case *parser.StmtExpression:
+		if isPure(node.Expression) {
+			l.report(firstToken(node.Expression), RuleUselessExpression, SeverityWarning, lintUselessExpression)
+		}
+	}
+	return true
+}
+
+// checkUnreachable reports the first statement following the return, throw, break or continue.
+func (l *linter) checkUnreachable(stmts []parser.Stmt) {
+	for index, stmt := range stmts[:max(len(stmts)-1, 0)] {
+		switch stmt.(type) {
+		case *parser.StmtReturn, *parser.StmtThrow, *parser.StmtBreak, *parser.StmtContinue:
+			l.report(firstToken(stmts[index+1]), RuleUnreachableCode, SeverityWarning, lintUnreachableCode)
+			return
+		}
+	}
+}
+
+// constantCondition returns the truthiness of the literal condition, ok is false for the other expressions.
+func constantCondition(condition parser.Expr) (value, ok bool) {
+	for {
+		grouping, isGrouping := condition.(*parser.ExprGrouping)
+		if !isGrouping {
+			break
+		}
+		condition = grouping.Expression
+	}
+	if literal, isLiteral := condition.(*parser.ExprLiteral); isLiteral {
+		// nil and false are falsey, as in interpreter.isTruthy
+		return literal.Value != nil && literal.Value != false, true
+	}
+	return false, false
+}
+
+// isPure reports whether evaluating the expression has no side effects besides the runtime errors.
+func isPure(expr parser.Expr) bool {
+	pure := true
+	parser.Walk(expr, func(node any) bool {
+		switch node.(type) {
+		case *parser.ExprCall, *parser.ExprAssign, *parser.ExprSet, *parser.ExprPostfix:
+			pure = false
+		case *parser.ExprFunction:
+			// the function body is not evaluated
+			return false
+		}
+		return pure
+	})
+	return pure
+}
+
+// firstToken returns the token of the node that comes first in the source, nil if the node has none.
+func firstToken(node any) *token.Token {
+	var first *token.Token
+	parser.Walk(node, func(node any) bool {
+		for _, tok := range nodeTokens(node) {
+			if tok != nil && (first == nil || tok.Line < first.Line || tok.Line == first.Line && tok.Column < first.Column) {
+				first = tok
+			}
+		}
+		return true
+	})
+	return first
+}
+
+// nodeTokens returns the tokens the node holds directly.
+func nodeTokens(node any) []*token.Token {
+	switch node := node.(type) {
+	case *parser.ExprAssign:
+		return []*token.Token{node.Name}
+	case *parser.ExprBinary:
+		return []*token.Token{node.Operator}
+	case *parser.ExprCall:
+		return []*token.Token{node.CloseParen}
+	case *parser.ExprFunction:
+		return node.Parameters
+	case *parser.ExprGet:
+		return []*token.Token{node.Name}
+	case *parser.ExprLiteral:
+		return []*token.Token{node.Token}
+	case *parser.ExprLogical:
+		return []*token.Token{node.Operator}
+	case *parser.ExprMap:
+		return []*token.Token{node.Brace}
+	case *parser.ExprPostfix:
+		return []*token.Token{node.Operator}
+	case *parser.ExprSet:
+		return []*token.Token{node.Name}
+	case *parser.ExprSuper:
+		return []*token.Token{node.Keyword}
+	case *parser.ExprThis:
+		return []*token.Token{node.Keyword}
+	case *parser.ExprTypeCheck:
+		return []*token.Token{node.Keyword}
+	case *parser.ExprUnary:
+		return []*token.Token{node.Operator}
+	case *parser.ExprVariable:
+		return []*token.Token{node.Name}
+	case *parser.StmtClass:
+		return []*token.Token{node.Name}
+	case *parser.StmtFunction:
+		return []*token.Token{node.Name}
+	case *parser.StmtPrint:
+		return []*token.Token{node.Keyword}
+	case *parser.StmtReturn:
+		return []*token.Token{node.Keyword}
+	case *parser.StmtVar:
+		return []*token.Token{node.Name}
+	case *parser.StmtForEach:
+		return []*token.Token{node.Name}
+	case *parser.StmtTry:
+		return []*token.Token{node.CatchName}
+	case *parser.StmtThrow:
+		return []*token.Token{node.Keyword}
+	case *parser.StmtBreak:
+		return []*token.Token{node.Keyword}
+	case *parser.StmtContinue:
+		return []*token.Token{node.Keyword}
+	case *parser.StmtEmpty:
+		return []*token.Token{node.Semicolon}
+	}
+	return nil
+}
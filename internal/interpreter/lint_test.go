@@ -0,0 +1,95 @@
+package interpreter_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/interpreter"
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	program := `fun f(a) {
+  var unused = 1;
+  {
+    var a = 2;
+    print a;
+  }
+  return a;
+  print "never";
+}
+if (false) print f(1);
+while (nil) {}
+f == nil;
+for (var i = 0; i < 2; i = i + 1) {
+  break;
+  continue;
+}
+if (true) print 1; else print 2;
+class A < A {}
+`
+	testcases := []struct {
+		name     string
+		profile  string
+		in       string
+		expected []string
+	}{
+		{
+			name:    "default",
+			profile: "default",
+			in:      program,
+			expected: []string{
+				"[line 2, col 7] error: Local variable is not used. (unused-variable)",
+				"[line 4, col 9] warning: Local variable shadows the variable in the enclosing scope. (shadowing)",
+				"[line 8, col 3] warning: Unreachable code. (unreachable-code)",
+				"[line 10, col 5] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 11, col 8] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 12, col 1] warning: Expression result is not used. (useless-expression)",
+				"[line 15, col 3] warning: Unreachable code. (unreachable-code)",
+				"[line 17, col 5] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 18, col 11] error: A class can't inherit from itself. (resolve)",
+			},
+		},
+		{
+			name:    "non-strict",
+			profile: "non-strict",
+			in:      program,
+			expected: []string{
+				"[line 2, col 7] warning: Local variable is not used. (unused-variable)",
+				"[line 4, col 9] warning: Local variable shadows the variable in the enclosing scope. (shadowing)",
+				"[line 8, col 3] warning: Unreachable code. (unreachable-code)",
+				"[line 10, col 5] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 11, col 8] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 12, col 1] warning: Expression result is not used. (useless-expression)",
+				"[line 15, col 3] warning: Unreachable code. (unreachable-code)",
+				"[line 17, col 5] warning: Constant condition, the branch is never executed. (dead-branch)",
+				"[line 18, col 11] error: A class can't inherit from itself. (resolve)",
+			},
+		},
+		{name: "clean", profile: "default", in: "fun f(a) { if (a) return a; return -a; } print f(1);"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			tokens, err := scanner.NewScanner(tc.in, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+
+			var findings []string
+			for _, finding := range interpreter.Lint(stmts, tc.profile) {
+				findings = append(findings, finding.String())
+			}
+			assert.Equal(t, tc.expected, findings)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/token"
+)
+
+// FormatLocals prints the variable references of the resolved statements, one per line in the source order,
+// with the scope distance the resolver recorded in the locals (Interpreter.Locals); unresolved are globals.
+//
+//	[line 3, col 12] x -> 1
+//	[line 4, col 5] clock -> global
+func FormatLocals(stmts []parser.Stmt, locals map[parser.Expr]Local) string {
+	var sb strings.Builder
+	parser.Walk(stmts, func(node any) bool {
+		var name *token.Token
+		switch node := node.(type) {
+		case *parser.ExprVariable:
+			name = node.Name
+		case *parser.ExprAssign:
+			name = node.Name
+		case *parser.ExprThis:
+			name = node.Keyword
+		case *parser.ExprSuper:
+			name = node.Keyword
+		default:
+			return true
+		}
+
+		resolved := "global"
+		if local, ok := locals[node.(parser.Expr)]; ok {
+			resolved = fmt.Sprint(local.Depth)
+		}
+		fmt.Fprintf(&sb, "[%s] %s -> %s\n", name.Position(), name.Lexeme, resolved)
+		return true
+	})
+	return sb.String()
+}
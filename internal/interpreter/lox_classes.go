@@ -2,8 +2,10 @@ package interpreter
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
 	"github.com/leonardinius/golox/internal/token"
 )
 
@@ -29,16 +31,40 @@ type LoxClass struct {
 	Methods map[string]*LoxFunction
 	// Constructor method
 	Init *LoxFunction
+
+	// Instance fields with default values, initialized before the constructor.
+	Fields []*parser.StmtVar
+	// Class declaration environment, the fields initializers are evaluated in.
+	FieldsEnv *environment
+
+	// methodCache are the methods of the inheritance chain by name, filled once in NewLoxClass.
+	// The classes are immutable once defined, the cache is read-only and safe to share between interpreters.
+	methodCache map[string]*LoxFunction
 }
 
 func NewLoxClass(name string, superClass *LoxClass, methods, classMethods map[string]*LoxFunction) *LoxClass {
-	metaClass := &LoxClass{Name: name + " metaclass", Methods: classMethods}
+	metaClass := &LoxClass{Name: name + " metaclass", Methods: classMethods, methodCache: chainMethods(nil, classMethods)}
+	cache := chainMethods(superClass, methods)
 
 	if init, ok := methods["init"]; ok {
-		return &LoxClass{Name: name, SuperClass: superClass, Methods: methods, Init: init}
+		return &LoxClass{Name: name, SuperClass: superClass, Methods: methods, Init: init, methodCache: cache}
 	}
 
-	return &LoxClass{Name: name, SuperClass: superClass, Methods: methods, MetaClass: metaClass}
+	return &LoxClass{Name: name, SuperClass: superClass, Methods: methods, MetaClass: metaClass, methodCache: cache}
+}
+
+// chainMethods merges the methods of the inheritance chain, the nearest definition wins.
+func chainMethods(superClass *LoxClass, methods map[string]*LoxFunction) map[string]*LoxFunction {
+	merged := make(map[string]*LoxFunction, len(methods))
+	for cl := superClass; cl != nil; cl = cl.SuperClass {
+		for name, method := range cl.Methods {
+			if _, ok := merged[name]; !ok {
+				merged[name] = method
+			}
+		}
+	}
+	maps.Copy(merged, methods)
+	return merged
 }
 
 // Arity implements Callable.
@@ -52,6 +78,9 @@ func (l *LoxClass) Arity() Arity {
 // Call implements Callable.
 func (l *LoxClass) Call(interpreter *interpreter, arguments []any) (any, error) {
 	newInstance := &objectInstance{Class: l, Fields: make(map[string]any)}
+	if err := l.initFields(interpreter, newInstance); err != nil {
+		return nil, err
+	}
 	if init := l.FindInit(); init != nil {
 		return init.Bind(newInstance).Call(interpreter, arguments)
 	}
@@ -86,15 +115,52 @@ func (l *LoxClass) Set(name *token.Token, value any) (any, error) {
 	return value, nil
 }
 
+// initFields sets the instance fields default values, superclass fields first.
+// The initializers are evaluated with `this` bound to the new instance.
+func (l *LoxClass) initFields(interpreter *interpreter, instance *objectInstance) error {
+	if l.SuperClass != nil {
+		if err := l.SuperClass.initFields(interpreter, instance); err != nil {
+			return err
+		}
+	}
+	if len(l.Fields) == 0 {
+		return nil
+	}
+
+	env := l.FieldsEnv.Nest()
+	env.Define("this", instance)
+	oldEnv := interpreter.setEnv(env)
+	defer interpreter.setEnv(oldEnv)
+
+	for _, field := range l.Fields {
+		var value any
+		if field.Initializer != nil {
+			var err error
+			if value, err = interpreter.evaluate(field.Initializer); err != nil {
+				return err
+			}
+		}
+		instance.Fields[field.Name.Lexeme] = value
+	}
+
+	return nil
+}
+
+// FindMethod returns the nearest method in the inheritance chain, nil if not found.
+// The classes created with NewLoxClass look up the prefilled cache, the cache is never written here.
 func (l *LoxClass) FindMethod(name string) *LoxFunction {
-	cl := l
-	for cl != nil {
+	if l == nil {
+		return nil
+	}
+	if l.methodCache != nil {
+		return l.methodCache[name]
+	}
+
+	for cl := l; cl != nil; cl = cl.SuperClass {
 		if method, ok := cl.Methods[name]; ok {
 			return method
 		}
-		cl = cl.SuperClass
 	}
-
 	return nil
 }
 
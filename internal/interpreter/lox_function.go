@@ -15,11 +15,45 @@ func (r *ReturnValueError) Error() string {
 	return fmt.Sprintf("fatal value: %v", r.Value)
 }
 
+// ThrowValueError is the value thrown by the throw statement.
+type ThrowValueError struct {
+	Value   any
+	message string
+}
+
+func (t *ThrowValueError) Error() string {
+	return t.message
+}
+
+// ExitError terminates the program with the exit code, see the exit native function.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// StepHookError wraps the step hook error, it aborts the execution and is never caught by the script.
+type StepHookError struct {
+	Err error
+}
+
+func (e *StepHookError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StepHookError) Unwrap() error {
+	return e.Err
+}
+
 type LoxFunction struct {
 	Name        *token.Token
 	Fn          *parser.ExprFunction
 	Env         *environment
 	IsIntialize bool
+	// IsGetter methods are invoked on property access.
+	IsGetter bool
 }
 
 func NewLoxFunction(name *token.Token, fn *parser.ExprFunction, env *environment, isInitialize bool) *LoxFunction {
@@ -28,14 +62,29 @@ func NewLoxFunction(name *token.Token, fn *parser.ExprFunction, env *environment
 
 // Arity implements Callable.
 func (l *LoxFunction) Arity() Arity {
+	if l.Fn.IsVariadic {
+		return ArityVarArgs
+	}
 	return Arity(len(l.Fn.Parameters))
 }
 
+// requiredArity is the number of the parameters before the rest parameter, if any.
+func (l *LoxFunction) requiredArity() int {
+	if l.Fn.IsVariadic {
+		return len(l.Fn.Parameters) - 1
+	}
+	return len(l.Fn.Parameters)
+}
+
 // Call implements Callable.
 func (l *LoxFunction) Call(interpreter *interpreter, arguments []any) (any, error) {
 	env := l.Env.Nest()
 
 	for idx, e := range l.Fn.Parameters {
+		if l.Fn.IsVariadic && idx == len(l.Fn.Parameters)-1 {
+			env.Define(e.Lexeme, NewStdArray(append([]any{}, arguments[idx:]...)))
+			break
+		}
 		env.Define(e.Lexeme, arguments[idx])
 	}
 
@@ -47,7 +96,7 @@ func (l *LoxFunction) Call(interpreter *interpreter, arguments []any) (any, erro
 		return nil, err
 	}
 	if l.IsIntialize {
-		return l.Env.GetAt(0, "this")
+		return l.Env.GetAt(0, 0, "this")
 	}
 	return value, nil
 }
@@ -55,7 +104,9 @@ func (l *LoxFunction) Call(interpreter *interpreter, arguments []any) (any, erro
 func (l *LoxFunction) Bind(instance LoxInstance) *LoxFunction {
 	env := l.Env.Nest()
 	env.Define("this", instance)
-	return NewLoxFunction(l.Name, l.Fn, env, l.IsIntialize)
+	bound := NewLoxFunction(l.Name, l.Fn, env, l.IsIntialize)
+	bound.IsGetter = l.IsGetter
+	return bound
 }
 
 func (l *LoxFunction) returnValue(err error) (any, error) {
@@ -0,0 +1,47 @@
+package interpreter
+
+// Pool is a thread safe pool of interpreters.
+// Every evaluation is served exclusively by one of the pooled interpreters,
+// the user state (globals) is kept per interpreter instance.
+//
+// The options are shared between the pooled interpreters,
+// so they must not share mutable state (e.g. WithGlobals, non thread safe writers).
+type Pool struct {
+	interpreters chan *interpreter
+}
+
+// NewPool creates a pool of size interpreters, created with the given options.
+func NewPool(size int, options ...InterpreterOption) *Pool {
+	size = max(size, 1)
+
+	pool := &Pool{interpreters: make(chan *interpreter, size)}
+	for range size {
+		pool.interpreters <- NewInterpreter(options...)
+	}
+
+	return pool
+}
+
+// Eval evaluates the source with one of the pooled interpreters.
+// Blocks until an interpreter is available.
+//
+// Thread safe.
+func (p *Pool) Eval(source string) (string, error) {
+	i := p.acquire()
+	defer p.release(i)
+
+	return i.Eval(source)
+}
+
+// Size returns the number of the pooled interpreters.
+func (p *Pool) Size() int {
+	return cap(p.interpreters)
+}
+
+func (p *Pool) acquire() *interpreter {
+	return <-p.interpreters
+}
+
+func (p *Pool) release(i *interpreter) {
+	p.interpreters <- i
+}
@@ -0,0 +1,86 @@
+package interpreter_test
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/interpreter"
+	"github.com/leonardinius/golox/internal/loxerrors"
+)
+
+func TestPoolEvalConcurrently(t *testing.T) {
+	t.Parallel()
+
+	pool := interpreter.NewPool(4,
+		interpreter.WithStdout(io.Discard),
+		interpreter.WithStderr(io.Discard),
+		interpreter.WithErrorReporter(loxerrors.NewErrReporter(io.Discard)),
+	)
+	assert.Equal(t, 4, pool.Size())
+
+	var wg sync.WaitGroup
+	for n := range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			script := fmt.Sprintf(`var a = %d; fun twice(x) { return x * 2; } print a; twice(a);`, n)
+			value, err := pool.Eval(script)
+			if assert.NoError(t, err) {
+				assert.Equal(t, strconv.Itoa(n*2), value)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPooledInterpretersShareBuiltinsOnly(t *testing.T) {
+	t.Parallel()
+
+	pool := interpreter.NewPool(2,
+		interpreter.WithStdout(io.Discard),
+		interpreter.WithErrorReporter(loxerrors.NewErrReporter(io.Discard)),
+	)
+
+	// the pool hands the interpreters out in turn, the second script runs on the other one
+	value, err := pool.Eval(`var secret = 1; clock = "shadowed"; secret;`)
+	require.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	value, err = pool.Eval(`defined("secret");`)
+	require.NoError(t, err)
+	assert.Equal(t, "false", value)
+	value, err = pool.Eval(`clock;`)
+	require.NoError(t, err)
+	assert.Equal(t, `"shadowed"`, value, "the first interpreter keeps its globals")
+	value, err = pool.Eval(`clock;`)
+	require.NoError(t, err)
+	assert.Equal(t, "<native fn>", value)
+	value, err = pool.Eval(`Array(2).length;`)
+	require.NoError(t, err)
+	assert.Equal(t, "2", value)
+}
+
+// The builtin classes are shared by all the interpreters, run with -race.
+func TestSharedClassesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eval := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard))
+			value, err := eval.Eval(`print hostInfo(); hostInfo().version;`)
+			if assert.NoError(t, err) {
+				assert.Equal(t, `"devel"`, value)
+			}
+		}()
+	}
+	wg.Wait()
+}
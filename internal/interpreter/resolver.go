@@ -1,9 +1,9 @@
 package interpreter
 
 import (
-	"container/list"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
@@ -12,7 +12,26 @@ import (
 )
 
 type Resolver interface {
+	// Resolve resolves the statements, returns the joined errors.
+	// The warnings are reported with the interpreter's ErrReporter.
 	Resolve(statements []parser.Stmt) error
+	// ResolveDetailed resolves the statements, the errors and warnings are reported separately.
+	ResolveDetailed(statements []parser.Stmt) *ResolveResult
+}
+
+// ResolveResult is the outcome of the resolution.
+type ResolveResult struct {
+	// Errors are the problems the program can't be interpreted with.
+	Errors []error
+	// Warnings are the problems demoted by the resolver profile, the program can still be interpreted.
+	Warnings []error
+	// Notes are the style diagnostics for the linter, e.g. the shadowed local variables.
+	Notes []error
+}
+
+// Err returns the joined errors, nil if there are none.
+func (r *ResolveResult) Err() error {
+	return errors.Join(r.Errors...)
 }
 
 type VarState int
@@ -45,17 +64,24 @@ const (
 type ResolverVariable struct {
 	Name  *token.Token
 	State VarState
+	// Slot is the index of the variable in its scope, in the definition order.
+	Slot int
 }
 
 type resolver struct {
 	interpreter     *interpreter
-	scopes          *list.List
+	scopes          []map[string]*ResolverVariable
 	err             []error
+	warnings        []error
+	notes           []error
 	currentFunction FunctionType
 	currentClass    ClassType
-	profile         string
+	// loopDepth is the loops nesting of the current function, break and continue are valid only inside a loop.
+	loopDepth int
+	profile   string
 }
 
+// profiles lists the errors demoted to warnings by the resolver profile.
 var profiles map[string][]error = map[string][]error{
 	"default": {},
 	"strict":  {},
@@ -72,7 +98,6 @@ func NewResolver(interpreterInstance Interpreter, profile string) Resolver {
 
 	newResolver := &resolver{
 		interpreter:     interpreterPtr,
-		scopes:          list.New(),
 		err:             nil,
 		currentFunction: FnTypeNone,
 		currentClass:    CTypeNone,
@@ -84,9 +109,18 @@ func NewResolver(interpreterInstance Interpreter, profile string) Resolver {
 
 // Resolve implements Resolver.
 func (r *resolver) Resolve(statements []parser.Stmt) error {
-	r.err = nil
+	result := r.ResolveDetailed(statements)
+	for _, warning := range result.Warnings {
+		r.interpreter.ErrReporter.ReportWarning(warning)
+	}
+	return result.Err()
+}
+
+// ResolveDetailed implements Resolver.
+func (r *resolver) ResolveDetailed(statements []parser.Stmt) *ResolveResult {
+	r.err, r.warnings, r.notes = nil, nil, nil
 	r.resolveStmts(statements)
-	return errors.Join(r.err...)
+	return &ResolveResult{Errors: r.err, Warnings: r.warnings, Notes: r.notes}
 }
 
 // VisitStmtBlock implements parser.StmtVisitor.
@@ -123,6 +157,12 @@ func (r *resolver) VisitStmtClass(stmtClass *parser.StmtClass) (any, error) {
 
 	r.defineInternal("this")
 
+	for _, field := range stmtClass.Fields {
+		if field.Initializer != nil {
+			r.resolveExpr(field.Initializer)
+		}
+	}
+
 	for _, method := range stmtClass.ClassMethods {
 		r.resolveFunction(method.Fn, FnTypeClassMethod)
 	}
@@ -140,11 +180,22 @@ func (r *resolver) VisitStmtClass(stmtClass *parser.StmtClass) (any, error) {
 
 // VisitStmtBreak implements parser.StmtVisitor.
 func (r *resolver) VisitStmtBreak(stmtBreak *parser.StmtBreak) (any, error) {
+	if r.loopDepth == 0 {
+		r.reportError(stmtBreak.Keyword, loxerrors.ErrParseBreakOutsideLoop)
+	}
 	return nil, errNilnil
 }
 
 // VisitStmtContinue implements parser.StmtVisitor.
 func (r *resolver) VisitStmtContinue(stmtContinue *parser.StmtContinue) (any, error) {
+	if r.loopDepth == 0 {
+		r.reportError(stmtContinue.Keyword, loxerrors.ErrParseContinueOutsideLoop)
+	}
+	return nil, errNilnil
+}
+
+// VisitStmtEmpty implements parser.StmtVisitor.
+func (r *resolver) VisitStmtEmpty(stmtEmpty *parser.StmtEmpty) (any, error) {
 	return nil, errNilnil
 }
 
@@ -168,7 +219,19 @@ func (r *resolver) VisitStmtFor(stmtFor *parser.StmtFor) (any, error) {
 		r.resolveExpr(stmtFor.Increment)
 	}
 
-	r.resolveStmt(stmtFor.Body)
+	r.resolveLoopBody(stmtFor.Body)
+	return nil, errNilnil
+}
+
+// VisitStmtForEach implements parser.StmtVisitor.
+func (r *resolver) VisitStmtForEach(stmtForEach *parser.StmtForEach) (any, error) {
+	r.resolveExpr(stmtForEach.Iterable)
+
+	r.beginScope()
+	defer r.endScope()
+	r.declare(stmtForEach.Name)
+	r.define(stmtForEach.Name)
+	r.resolveLoopBody(stmtForEach.Body)
 	return nil, errNilnil
 }
 
@@ -193,7 +256,9 @@ func (r *resolver) VisitStmtIf(stmtIf *parser.StmtIf) (any, error) {
 
 // VisitStmtPrint implements parser.StmtVisitor.
 func (r *resolver) VisitStmtPrint(stmtPrint *parser.StmtPrint) (any, error) {
-	r.resolveExpr(stmtPrint.Expression)
+	for _, expr := range stmtPrint.Expressions {
+		r.resolveExpr(expr)
+	}
 	return nil, errNilnil
 }
 
@@ -209,6 +274,33 @@ func (r *resolver) VisitStmtReturn(stmtReturn *parser.StmtReturn) (any, error) {
 	return nil, errNilnil
 }
 
+// VisitStmtTry implements parser.StmtVisitor.
+func (r *resolver) VisitStmtTry(stmtTry *parser.StmtTry) (any, error) {
+	r.beginScope()
+	r.resolveStmts(stmtTry.Body)
+	r.endScope()
+
+	if stmtTry.CatchName != nil {
+		r.beginScope()
+		r.declare(stmtTry.CatchName)
+		r.define(stmtTry.CatchName)
+		r.resolveStmts(stmtTry.CatchBody)
+		r.endScope()
+	}
+
+	r.beginScope()
+	r.resolveStmts(stmtTry.FinallyBody)
+	r.endScope()
+
+	return nil, errNilnil
+}
+
+// VisitStmtThrow implements parser.StmtVisitor.
+func (r *resolver) VisitStmtThrow(stmtThrow *parser.StmtThrow) (any, error) {
+	r.resolveExpr(stmtThrow.Value)
+	return nil, errNilnil
+}
+
 // VisitStmtVar implements parser.StmtVisitor.
 func (r *resolver) VisitStmtVar(stmtVar *parser.StmtVar) (any, error) {
 	r.declare(stmtVar.Name)
@@ -222,7 +314,24 @@ func (r *resolver) VisitStmtVar(stmtVar *parser.StmtVar) (any, error) {
 // VisitStmtWhile implements parser.StmtVisitor.
 func (r *resolver) VisitStmtWhile(stmtWhile *parser.StmtWhile) (any, error) {
 	r.resolveExpr(stmtWhile.Condition)
-	r.resolveStmt(stmtWhile.Body)
+	r.resolveLoopBody(stmtWhile.Body)
+	return nil, errNilnil
+}
+
+// VisitExprArray implements parser.ExprVisitor.
+func (r *resolver) VisitExprArray(exprArray *parser.ExprArray) (any, error) {
+	for _, element := range exprArray.Elements {
+		r.resolveExpr(element)
+	}
+	return nil, errNilnil
+}
+
+// VisitExprMap implements parser.ExprVisitor.
+func (r *resolver) VisitExprMap(exprMap *parser.ExprMap) (any, error) {
+	for index, key := range exprMap.Keys {
+		r.resolveExpr(key)
+		r.resolveExpr(exprMap.Values[index])
+	}
 	return nil, errNilnil
 }
 
@@ -314,6 +423,19 @@ func (r *resolver) VisitExprThis(exprThis *parser.ExprThis) (any, error) {
 	return nil, errNilnil
 }
 
+// VisitExprTypeCheck implements parser.ExprVisitor.
+func (r *resolver) VisitExprTypeCheck(exprTypeCheck *parser.ExprTypeCheck) (any, error) {
+	r.resolveExpr(exprTypeCheck.Instance)
+	r.resolveExpr(exprTypeCheck.Class)
+	return nil, errNilnil
+}
+
+// VisitExprPostfix implements parser.ExprVisitor.
+func (r *resolver) VisitExprPostfix(exprPostfix *parser.ExprPostfix) (any, error) {
+	r.resolveExpr(exprPostfix.Target)
+	return nil, errNilnil
+}
+
 // VisitExprUnary implements parser.ExprVisitor.
 func (r *resolver) VisitExprUnary(exprUnary *parser.ExprUnary) (any, error) {
 	r.resolveExpr(exprUnary.Right)
@@ -331,19 +453,26 @@ func (r *resolver) VisitExprVariable(exprVariable *parser.ExprVariable) (any, er
 }
 
 func (r *resolver) beginScope() {
-	r.scopes.PushBack(map[string]*ResolverVariable{})
+	r.scopes = append(r.scopes, map[string]*ResolverVariable{})
 }
 
 func (r *resolver) endScope() {
 	if scope, ok := r.peekScope(); ok {
-		for _, name := range scope {
-			if name.State == VarStateDefined {
-				r.reportError(name.Name, loxerrors.ErrParseLocalVariableNotUsed)
+		// reported in the slots (definition) order, the scope map order is random
+		unused := make([]*ResolverVariable, 0, len(scope))
+		for _, variable := range scope {
+			if variable.State == VarStateDefined {
+				unused = append(unused, variable)
 			}
 		}
+		slices.SortFunc(unused, func(a, b *ResolverVariable) int { return a.Slot - b.Slot })
+		for _, variable := range unused {
+			r.reportError(variable.Name, loxerrors.ErrParseLocalVariableNotUsed)
+		}
 	}
 
-	r.scopes.Remove(r.scopes.Back())
+	r.scopes[len(r.scopes)-1] = nil
+	r.scopes = r.scopes[:len(r.scopes)-1]
 }
 
 func (r *resolver) resolveStmts(stmts []parser.Stmt) {
@@ -360,12 +489,20 @@ func (r *resolver) resolveExpr(expr parser.Expr) {
 	_, _ = expr.Accept(r)
 }
 
+// resolveLoopBody resolves the loop body, break and continue are valid there.
+func (r *resolver) resolveLoopBody(body parser.Stmt) {
+	r.loopDepth++
+	defer func() { r.loopDepth-- }()
+	r.resolveStmt(body)
+}
+
 func (r *resolver) resolveFunction(function *parser.ExprFunction, declaration FunctionType) {
-	enclosingFunction := r.currentFunction
+	enclosingFunction, enclosingLoopDepth := r.currentFunction, r.loopDepth
 	r.beginScope()
-	r.currentFunction = declaration
+	// the loops don't continue into the function body
+	r.currentFunction, r.loopDepth = declaration, 0
 
-	defer func() { r.currentFunction = enclosingFunction }()
+	defer func() { r.currentFunction, r.loopDepth = enclosingFunction, enclosingLoopDepth }()
 	defer r.endScope()
 
 	for _, param := range function.Parameters {
@@ -377,19 +514,15 @@ func (r *resolver) resolveFunction(function *parser.ExprFunction, declaration Fu
 }
 
 func (r *resolver) resolveLocal(expr parser.Expr, tok *token.Token, isRead bool) {
-	depth := r.scopes.Len()
-	back := r.scopes.Back()
-	for i := range depth {
-		scope := r.scopeFromListElem(back)
-		if _, ok := scope[tok.Lexeme]; ok {
-			r.interpreter.resolve(expr, i)
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if variable, ok := r.scopes[i][tok.Lexeme]; ok {
+			r.interpreter.resolve(expr, Local{Depth: len(r.scopes) - 1 - i, Slot: variable.Slot})
 
 			if isRead {
-				scope[tok.Lexeme].State = VarStateRead
+				variable.State = VarStateRead
 			}
 			return
 		}
-		back = back.Prev()
 	}
 }
 
@@ -397,11 +530,23 @@ func (r *resolver) declare(tok *token.Token) {
 	if scope, ok := r.peekScope(); ok {
 		if _, ok := scope[tok.Lexeme]; ok {
 			r.reportError(tok, loxerrors.ErrParseCantDuplicateVariableDefinition)
+		} else if r.isEnclosingLocal(tok.Lexeme) {
+			r.notes = append(r.notes, loxerrors.NewParseError(tok, loxerrors.ErrParseLocalVariableShadowsOuter))
 		}
-		scope[tok.Lexeme] = &ResolverVariable{Name: tok, State: VarStateDeclared}
+		scope[tok.Lexeme] = &ResolverVariable{Name: tok, State: VarStateDeclared, Slot: len(scope)}
 	}
 }
 
+// isEnclosingLocal reports whether the name is declared in the enclosing local scopes, the current scope excluded.
+func (r *resolver) isEnclosingLocal(name string) bool {
+	for i := len(r.scopes) - 2; i >= 0; i-- {
+		if v, ok := r.scopes[i][name]; ok && v.Name != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *resolver) define(tok *token.Token) {
 	if scope, ok := r.peekScope(); ok {
 		scope[tok.Lexeme].State = VarStateDefined
@@ -410,15 +555,15 @@ func (r *resolver) define(tok *token.Token) {
 
 func (r *resolver) defineInternal(name string) {
 	if scope, ok := r.peekScope(); ok {
-		scope[name] = &ResolverVariable{Name: nil, State: VarStateRead}
+		scope[name] = &ResolverVariable{Name: nil, State: VarStateRead, Slot: len(scope)}
 	}
 }
 
 func (r *resolver) peekScope() (map[string]*ResolverVariable, bool) {
-	if r.scopes.Len() == 0 {
+	if len(r.scopes) == 0 {
 		return nil, false
 	}
-	return r.scopeFromListElem(r.scopes.Back()), true
+	return r.scopes[len(r.scopes)-1], true
 }
 
 func (r *resolver) peekScopeVar(name string) (*ResolverVariable, bool) {
@@ -430,14 +575,11 @@ func (r *resolver) peekScopeVar(name string) (*ResolverVariable, bool) {
 	return nil, false
 }
 
-func (r *resolver) scopeFromListElem(el *list.Element) map[string]*ResolverVariable {
-	return el.Value.(map[string]*ResolverVariable)
-}
-
 func (r *resolver) reportError(tok *token.Token, err error) {
-	if ignoredErrors, ok := profiles[r.profile]; ok {
-		for _, ignoredError := range ignoredErrors {
-			if errors.Is(err, ignoredError) {
+	if demotedErrors, ok := profiles[r.profile]; ok {
+		for _, demotedError := range demotedErrors {
+			if errors.Is(err, demotedError) {
+				r.warnings = append(r.warnings, loxerrors.NewParseError(tok, err))
 				return
 			}
 		}
@@ -449,13 +591,9 @@ func (r *resolver) reportError(tok *token.Token, err error) {
 func (r *resolver) String() string {
 	w := new(strings.Builder)
 
-	index := 0
 	delimiter := ""
-	element := r.scopes.Front()
-	for element != nil {
-		_, _ = fmt.Fprintf(w, "%s%d{%v}", delimiter, index, element.Value.(map[string]*ResolverVariable))
-		index++
-		element = element.Next()
+	for index, scope := range r.scopes {
+		_, _ = fmt.Fprintf(w, "%s%d{%v}", delimiter, index, scope)
 		delimiter = " ->"
 	}
 
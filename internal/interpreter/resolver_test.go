@@ -0,0 +1,160 @@
+package interpreter_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/interpreter"
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+func TestResolveDetailed(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		profile  string
+		in       string
+		errors   []string
+		warnings []string
+	}{
+		{name: `clean`, profile: "default", in: `{ var a = 1; print a; }`},
+		{name: `unused error`, profile: "default", in: `{ var a = 1; }`, errors: []string{"[line 1] Error at 'a' (col 7): Local variable is not used."}},
+		{name: `unused strict`, profile: "strict", in: `{ var a = 1; }`, errors: []string{"[line 1] Error at 'a' (col 7): Local variable is not used."}},
+		{name: `unused demoted`, profile: "non-strict", in: `{ var a = 1; }`, warnings: []string{"[line 1] Error at 'a' (col 7): Local variable is not used."}},
+		{
+			name: `unused in order`, profile: "non-strict", in: `{ var d = 1; var c = 2; var b = 3; var a = 4; fun f(z, y) {} }`,
+			warnings: []string{
+				"[line 1] Error at 'z' (col 53): Local variable is not used.",
+				"[line 1] Error at 'y' (col 56): Local variable is not used.",
+				"[line 1] Error at 'd' (col 7): Local variable is not used.",
+				"[line 1] Error at 'c' (col 18): Local variable is not used.",
+				"[line 1] Error at 'b' (col 29): Local variable is not used.",
+				"[line 1] Error at 'a' (col 40): Local variable is not used.",
+				"[line 1] Error at 'f' (col 51): Local variable is not used.",
+			},
+		},
+		{
+			name: `errors and warnings`, profile: "non-strict", in: "{ var a = 1; }\n\nclass A < A {}",
+			errors:   []string{"[line 3] Error at 'A' (col 11): A class can't inherit from itself."},
+			warnings: []string{"[line 1] Error at 'a' (col 7): Local variable is not used."},
+		},
+		{name: `break in loop`, profile: "default", in: `while (true) { fun f() { while (true) break; } f(); break; }`},
+		{
+			name: `break in function in loop`, profile: "default", in: `while (true) { fun f() { break; } f(); }`,
+			errors: []string{"[line 1] Error at 'break' (col 26): Must be inside a loop to use 'break'."},
+		},
+		{
+			name: `continue in lambda in loop`, profile: "default", in: `for (var x in [1]) { var f = fun () { continue; }; f(x); }`,
+			errors: []string{"[line 1] Error at 'continue' (col 39): Must be inside a loop to use 'continue'."},
+		},
+		{
+			name: `break in method in loop`, profile: "default", in: `while (true) { class A { m() { break; } } A(); }`,
+			errors: []string{"[line 1] Error at 'break' (col 32): Must be inside a loop to use 'break'."},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			tokens, err := scanner.NewScanner(tc.in, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+
+			collected := loxerrors.NewCollectingReporter()
+			i := interpreter.NewInterpreter(interpreter.WithErrorReporter(collected))
+			result := interpreter.NewResolver(i, tc.profile).ResolveDetailed(stmts)
+			assert.Equal(t, tc.errors, errorStrings(result.Errors))
+			assert.Equal(t, tc.warnings, errorStrings(result.Warnings))
+			assert.Empty(t, collected.All(), "ResolveDetailed returns the warnings, doesn't report them")
+			if tc.errors == nil {
+				require.NoError(t, result.Err())
+				require.NoError(t, interpreter.NewResolver(i, tc.profile).Resolve(stmts))
+			} else {
+				require.Error(t, result.Err())
+				require.Error(t, interpreter.NewResolver(i, tc.profile).Resolve(stmts))
+			}
+			assert.Equal(t, tc.warnings, errorStrings(collected.Warnings))
+			assert.Empty(t, collected.Errors)
+		})
+	}
+}
+
+func TestResolveNestedScopes(t *testing.T) {
+	t.Parallel()
+
+	script := `var g = 0;
+{
+  var a = 1;
+  {
+    var b = 2;
+    {
+      var unused = 3;
+      print a + b + g;
+    }
+  }
+}`
+
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	tokens, err := scanner.NewScanner(script, reporter).Scan()
+	require.NoError(t, err)
+	stmts, err := parser.NewParser(tokens, reporter).Parse()
+	require.NoError(t, err)
+
+	i := interpreter.NewInterpreter()
+	result := interpreter.NewResolver(i, "default").ResolveDetailed(stmts)
+	assert.Equal(t, []string{"[line 7] Error at 'unused' (col 11): Local variable is not used."}, errorStrings(result.Errors))
+	assert.Equal(t, `[line 8, col 13] a -> 2
+[line 8, col 17] b -> 1
+[line 8, col 21] g -> global
+`, interpreter.FormatLocals(stmts, i.Locals))
+}
+
+func TestFormatLocals(t *testing.T) {
+	t.Parallel()
+
+	script := `fun counter() {
+  var n = 0;
+  return fun () {
+    n = n + 1;
+    return n;
+  };
+}
+class A {
+  get() { return this; }
+}
+var c = counter();
+print c() + A().get;`
+
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	tokens, err := scanner.NewScanner(script, reporter).Scan()
+	require.NoError(t, err)
+	stmts, err := parser.NewParser(tokens, reporter).Parse()
+	require.NoError(t, err)
+	i := interpreter.NewInterpreter()
+	require.NoError(t, interpreter.NewResolver(i, "default").Resolve(stmts))
+
+	assert.Equal(t, `[line 4, col 5] n -> 1
+[line 4, col 9] n -> 1
+[line 5, col 12] n -> 1
+[line 9, col 18] this -> 1
+[line 11, col 9] counter -> global
+[line 12, col 7] c -> global
+[line 12, col 13] A -> global
+`, interpreter.FormatLocals(stmts, i.Locals))
+}
+
+func errorStrings(errs []error) []string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
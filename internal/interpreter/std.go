@@ -1,8 +1,17 @@
 package interpreter
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
 	"github.com/leonardinius/golox/internal/token"
@@ -10,23 +19,281 @@ import (
 
 var errNilnil error = nil
 
+// Version is the interpreter version reported by hostInfo(),
+// set at build time with `-ldflags "-X github.com/leonardinius/golox/internal/interpreter.Version=..."`.
+var Version = "devel"
+
+var hostInfoClass = &LoxClass{Name: "HostInfo"}
+
+// stdBuiltins returns the read-only environment with the native functions.
+// Created once and shared by all the interpreters.
+var stdBuiltins = sync.OnceValue(func() *environment {
+	return newReadonlyEnvironment(map[string]any{
+		"Array":         NativeFunctionVarArgs(StdFnCreateArray),
+		"assert":        NativeFunctionVarArgs(StdFnAssert),
+		"captureOutput": NativeFunction1(StdFnCaptureOutput),
+		"chr":           NativeFunction1(StdFnChr),
+		"clock":         NativeFunction0(StdFnTime),
+		"defined":       NativeFunction1(StdFnDefined),
+		"exit":          NativeFunction1(StdFnExit),
+		"hostInfo":      NativeFunction0(StdFnHostInfo),
+		"join":          NativeFunctionVarArgs(StdFnJoin),
+		"json_decode":   NativeFunction1(StdFnJSONDecode),
+		"json_encode":   NativeFunction1(StdFnJSONEncode),
+		"Map":           NativeFunction0(StdFnCreateMap),
+		"Math":          &StdMath{},
+		"num":           NativeFunction1(StdFnNum),
+		"ord":           NativeFunction1(StdFnOrd),
+		"paramNames":    NativeFunction1(StdFnParamNames),
+		"pprint":        NativeFunctionVarArgs(StdFnPPrint),
+		"random":        NativeFunction0(StdFnRandom),
+		"random_int":    NativeFunction1(StdFnRandomInt),
+		"read_line":     NativeFunction0(StdFnReadLine),
+		"sleep":         NativeFunction1(StdFnSleep),
+		"str":           NativeFunction1(StdFnStr),
+		"type":          NativeFunction1(StdFnType),
+		"undef":         NativeFunction1(StdFnUndef),
+	})
+})
+
 func StdFnTime(interpeter *interpreter) (any, error) {
 	return float64(time.Now().UnixMilli()) / 1000.0, nil
 }
 
 func StdFnPPrint(interpeter *interpreter, args ...any) (any, error) {
-	interpeter.print(args...)
-	return nil, errNilnil
+	return nil, interpeter.print(args...)
+}
+
+// StdFnJoin returns the values displayed and separated by the sep string, join(sep, ...values).
+func StdFnJoin(interpeter *interpreter, args ...any) (any, error) {
+	if len(args) == 0 {
+		return nil, loxerrors.ErrRuntimeCalleeMinArityError(1, len(args))
+	}
+	sep, ok := args[0].(string)
+	if !ok {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeString
+	}
+	return interpeter.join(sep, args[1:]...)
+}
+
+// StdFnNum converts the string to number, returns nil if the string is not a number.
+func StdFnNum(interpeter *interpreter, value any) (any, error) {
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return n, nil
+		}
+		return nil, errNilnil
+	}
+	return nil, loxerrors.ErrRuntimeArgumentMustBeNumberOrString
+}
+
+// StdFnChr returns the one character string for the Unicode code point.
+func StdFnChr(interpeter *interpreter, code any) (any, error) {
+	n, ok := code.(float64)
+	if !ok || n != math.Trunc(n) || n < 0 || n > unicode.MaxRune || !utf8.ValidRune(rune(n)) {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeCodePoint
+	}
+	return string(rune(n)), nil
+}
+
+// StdFnOrd returns the Unicode code point of the one character string.
+func StdFnOrd(interpeter *interpreter, char any) (any, error) {
+	s, ok := char.(string)
+	if !ok || utf8.RuneCountInString(s) != 1 {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeCharacter
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return float64(r), nil
+}
+
+// StdFnParamNames returns the array of the function parameter names,
+// empty for the native functions and the classes.
+func StdFnParamNames(interpeter *interpreter, fn any) (any, error) {
+	switch fn := fn.(type) {
+	case *LoxFunction:
+		names := make([]any, len(fn.Fn.Parameters))
+		for i, param := range fn.Fn.Parameters {
+			names[i] = param.Lexeme
+		}
+		return NewStdArray(names), nil
+	case Callable:
+		return NewStdArray([]any{}), nil
+	}
+	return nil, loxerrors.ErrRuntimeArgumentMustBeFunction
+}
+
+// StdFnReadLine reads the line from the interpreter Stdin, returns nil at EOF.
+func StdFnReadLine(interpeter *interpreter) (any, error) {
+	line, ok, err := interpeter.readLine()
+	if err != nil || !ok {
+		return nil, err
+	}
+	return line, nil
+}
+
+// StdFnAssert fails with the message (or loxerrors.ErrRuntimeAssertionFailed) if the condition is falsey.
+func StdFnAssert(interpeter *interpreter, args ...any) (any, error) {
+	switch len(args) {
+	case 1, 2:
+		break
+	case 0:
+		return nil, loxerrors.ErrRuntimeCalleeMinArityError(1, len(args))
+	default:
+		return nil, loxerrors.ErrRuntimeCalleeArityError(2, len(args))
+	}
+
+	if interpeter.isTruthy(args[0]) {
+		return nil, errNilnil
+	}
+	if len(args) == 2 {
+		message, err := interpeter.display(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(message)
+	}
+	return nil, loxerrors.ErrRuntimeAssertionFailed
+}
+
+// StdFnCaptureOutput calls the function with no arguments, returns what it printed instead of printing it.
+// The interpreter Stdout is restored afterwards, also on error.
+func StdFnCaptureOutput(interpeter *interpreter, fn any) (any, error) {
+	callable, ok := fn.(Callable)
+	if !ok || callable.Arity() != 0 {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeFunctionWithoutArguments
+	}
+
+	var sb strings.Builder
+	defer func(stdout io.Writer) { interpeter.Stdout = stdout }(interpeter.Stdout)
+	interpeter.Stdout = &sb
+
+	if _, err := callable.Call(interpeter, nil); err != nil {
+		return nil, err
+	}
+	return sb.String(), nil
+}
+
+// StdFnExit terminates the program with the integer exit code in [0, 255].
+// The exit is not caught by the try/catch, the finally blocks are executed.
+func StdFnExit(interpeter *interpreter, code any) (any, error) {
+	n, ok := code.(float64)
+	if !ok || n != math.Trunc(n) || n < 0 || n > 255 {
+		return nil, loxerrors.ErrRuntimeExitCodeMustBeInteger
+	}
+	return nil, &ExitError{Code: int(n)}
+}
+
+// StdFnSleep pauses the execution for the (fractional) number of seconds.
+// The sleep is interrupted once the interpretation times out.
+func StdFnSleep(interpeter *interpreter, seconds any) (any, error) {
+	s, ok := seconds.(float64)
+	if !ok || !(s >= 0) {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeNonNegativeNumber
+	}
+
+	// the longer sleeps are clamped, not to overflow the duration
+	duration := time.Duration(math.MaxInt64)
+	if s < float64(math.MaxInt64)/float64(time.Second) {
+		duration = time.Duration(s * float64(time.Second))
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil, errNilnil
+	case <-interpeter.ctx.Done():
+		return nil, interpeter.checkInterrupted()
+	}
+}
+
+// StdFnStr returns the value as it would be printed, honoring the user defined toString().
+func StdFnStr(interpeter *interpreter, value any) (any, error) {
+	return interpeter.display(value)
+}
+
+func StdFnType(interpeter *interpreter, arg any) (any, error) {
+	return typeName(arg), nil
+}
+
+// StdFnDefined reports whether the global variable with the given name exists.
+func StdFnDefined(interpeter *interpreter, name any) (any, error) {
+	if name, ok := name.(string); ok {
+		return interpeter.Globals.Has(name), nil
+	}
+	return nil, loxerrors.ErrRuntimeArgumentMustBeString
+}
+
+// StdFnUndef removes the global variable, reports whether it existed.
+// The builtins can't be removed, only the user (re)definitions of them.
+func StdFnUndef(interpeter *interpreter, name any) (any, error) {
+	if name, ok := name.(string); ok {
+		return interpeter.Globals.Undefine(name), nil
+	}
+	return nil, loxerrors.ErrRuntimeArgumentMustBeString
+}
+
+// StdFnHostInfo returns the HostInfo instance with the version and the capabilities granted to the scripts.
+func StdFnHostInfo(interpeter *interpreter) (any, error) {
+	info := NewObjectInstance(hostInfoClass)
+	info.Fields["version"] = Version
+	info.Fields["hasFileAccess"] = interpeter.opts.fileAccess
+	info.Fields["hasEnvAccess"] = interpeter.opts.envAccess
+	return info, nil
+}
+
+// typeName returns the Lox type name of the value.
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *StdArray:
+		return "array"
+	case *StdMap:
+		return "map"
+	case *LoxClass:
+		return "class"
+	case Callable:
+		return "function"
+	case LoxInstance:
+		return "instance"
+	}
+
+	return fmt.Sprintf("%T", value)
 }
 
-func StdFnCreateArray(interpeter *interpreter, arg any) (any, error) {
-	var size int
-	switch arg := arg.(type) {
+// maxArraySize limits the Array(size) preallocation, the larger sizes (incl. the int overflows) are invalid.
+const maxArraySize = 1 << 24
+
+func StdFnCreateArray(interpeter *interpreter, args ...any) (any, error) {
+	switch len(args) {
+	case 0:
+		return NewStdArray(nil), nil
+	case 1:
+		break
+	default:
+		return nil, loxerrors.ErrRuntimeCalleeArityError(1, len(args))
+	}
+
+	size := -1
+	switch arg := args[0].(type) {
 	case int:
 		size = arg
 	case float64:
-		size = int(arg)
-	default:
+		if arg == math.Trunc(arg) && arg >= 0 && arg <= maxArraySize {
+			size = int(arg)
+		}
+	}
+	if size < 0 || size > maxArraySize {
 		return nil, loxerrors.ErrRuntimeArrayInvalidArraySize
 	}
 
@@ -55,6 +322,32 @@ func (s *StdArray) Get(name *token.Token) (any, error) {
 		return NativeFunction2(func(interpeter *interpreter, arg1, arg2 any) (any, error) {
 			return s.setAt(name, arg1, arg2)
 		}), nil
+	case "push":
+		return NativeFunction1(func(interpeter *interpreter, arg1 any) (any, error) {
+			return s.push(arg1)
+		}), nil
+	case "pop":
+		return NativeFunction0(func(interpeter *interpreter) (any, error) {
+			return s.pop(name)
+		}), nil
+	case "append":
+		return NativeFunction1(func(interpeter *interpreter, arg1 any) (any, error) {
+			return s.append(name, arg1)
+		}), nil
+	case "indexOf":
+		return NativeFunction1(func(interpeter *interpreter, arg1 any) (any, error) {
+			index, err := s.indexOf(interpeter, arg1)
+			return float64(index), err
+		}), nil
+	case "contains":
+		return NativeFunction1(func(interpeter *interpreter, arg1 any) (any, error) {
+			index, err := s.indexOf(interpeter, arg1)
+			return index >= 0, err
+		}), nil
+	case "fill":
+		return NativeFunctionVarArgs(func(interpeter *interpreter, args ...any) (any, error) {
+			return s.fill(name, args...)
+		}), nil
 	}
 
 	return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeUndefinedProperty(name.Lexeme))
@@ -92,19 +385,112 @@ func (s *StdArray) setAt(name *token.Token, index, value any) (any, error) {
 	return nil, errNilnil
 }
 
+func (s *StdArray) push(value any) (any, error) {
+	s.values = append(s.values, value)
+	return nil, errNilnil
+}
+
+func (s *StdArray) pop(name *token.Token) (any, error) {
+	if len(s.values) == 0 {
+		return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeArrayEmpty)
+	}
+
+	last := len(s.values) - 1
+	value := s.values[last]
+	s.values[last] = nil
+	s.values = s.values[:last]
+	return value, nil
+}
+
+func (s *StdArray) append(name *token.Token, other any) (any, error) {
+	array, ok := other.(*StdArray)
+	if !ok {
+		return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeArrayCanOnlyAppendArrays)
+	}
+
+	s.values = append(s.values, array.values...)
+	return nil, errNilnil
+}
+
+// indexOf returns the index of the first element equal to the value, -1 if none.
+// The instances are compared with their equals(other) method.
+func (s *StdArray) indexOf(interpeter *interpreter, value any) (int, error) {
+	for index, element := range s.values {
+		equal, err := interpeter.valuesEqual(element, value)
+		if err != nil {
+			return -1, err
+		}
+		if equal {
+			return index, nil
+		}
+	}
+	return -1, nil
+}
+
+// fill sets the elements to the value, either all `fill(value)` or the [start, end) range `fill(value, start[, end])`.
+// The end defaults to the array length.
+func (s *StdArray) fill(name *token.Token, args ...any) (any, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeCalleeArityRangeError(1, 3, len(args)))
+	}
+
+	start, end := 0, len(s.values)
+	var err error
+	if len(args) > 1 {
+		if start, err = s.indexToInt(name, args[1]); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) > 2 {
+		if end, err = s.indexToInt(name, args[2]); err != nil {
+			return nil, err
+		}
+	}
+	if start < 0 || start > end || end > len(s.values) {
+		return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeArrayIndexOutOfRange)
+	}
+
+	for i := start; i < end; i++ {
+		s.values[i] = args[0]
+	}
+	return s, nil
+}
+
 func (s *StdArray) indexToInt(name *token.Token, index any) (int, error) {
 	switch index := index.(type) {
 	case int:
 		return index, nil
 	case float64:
-		return int(index), nil
+		// fractional indexes are rejected rather than truncated
+		if index == math.Trunc(index) {
+			return int(index), nil
+		}
 	}
 
 	return 0, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeArrayInvalidArrayIndex)
 }
 
 func (s *StdArray) String() string {
-	return fmt.Sprintf("%v", s.values)
+	str, _ := s.format(formatElement)
+	return str
+}
+
+// format renders the elements as `[1, 2]`, the nested values are formatted with the element func.
+func (s *StdArray) format(element func(any) (string, error)) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for index, value := range s.values {
+		if index > 0 {
+			sb.WriteString(", ")
+		}
+		str, err := element(value)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(str)
+	}
+	sb.WriteString("]")
+	return sb.String(), nil
 }
 
 func (s *StdArray) GoString() string {
@@ -116,3 +502,148 @@ var (
 	_ fmt.Stringer   = (*StdArray)(nil)
 	_ fmt.GoStringer = (*StdArray)(nil)
 )
+
+func StdFnCreateMap(interpeter *interpreter) (any, error) {
+	return NewStdMap(), nil
+}
+
+// StdMap is the map keyed by numbers or strings, iterated in the keys insertion order.
+type StdMap struct {
+	entries orderedMap
+}
+
+func NewStdMap() *StdMap {
+	return &StdMap{}
+}
+
+// Get implements LoxInstance.
+func (s *StdMap) Get(name *token.Token) (any, error) {
+	switch name.Lexeme {
+	case "size":
+		return float64(s.entries.len()), nil
+	case "has":
+		return NativeFunction1(func(interpeter *interpreter, key any) (any, error) {
+			if err := s.checkKey(name, key); err != nil {
+				return nil, err
+			}
+			_, ok := s.entries.get(key)
+			return ok, nil
+		}), nil
+	case "delete":
+		return NativeFunction1(func(interpeter *interpreter, key any) (any, error) {
+			if err := s.checkKey(name, key); err != nil {
+				return nil, err
+			}
+			return s.entries.delete(key), nil
+		}), nil
+	case "keys":
+		return NativeFunction0(func(interpeter *interpreter) (any, error) {
+			return NewStdArray(append([]any{}, s.entries.keys...)), nil
+		}), nil
+	case "get":
+		return NativeFunction1(func(interpeter *interpreter, key any) (any, error) {
+			if err := s.checkKey(name, key); err != nil {
+				return nil, err
+			}
+			value, _ := s.entries.get(key)
+			return value, nil
+		}), nil
+	case "set":
+		return NativeFunction2(func(interpeter *interpreter, key, value any) (any, error) {
+			if err := s.checkKey(name, key); err != nil {
+				return nil, err
+			}
+			s.entries.set(key, value)
+			return nil, errNilnil
+		}), nil
+	}
+
+	return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeUndefinedProperty(name.Lexeme))
+}
+
+// Set implements LoxInstance.
+func (s *StdMap) Set(name *token.Token, value any) (any, error) {
+	return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeMapsCantSetProperties)
+}
+
+func (s *StdMap) checkKey(name *token.Token, key any) error {
+	switch key.(type) {
+	case float64, string:
+		return nil
+	}
+	return loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeMapInvalidKey)
+}
+
+func (s *StdMap) String() string {
+	str, _ := s.format(formatElement)
+	return str
+}
+
+// format renders the entries as `{a: 1, b: 2}`, the keys and values are formatted with the element func.
+func (s *StdMap) format(element func(any) (string, error)) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for index, key := range s.entries.keys {
+		if index > 0 {
+			sb.WriteString(", ")
+		}
+		value, _ := s.entries.get(key)
+		keyStr, err := element(key)
+		if err != nil {
+			return "", err
+		}
+		valueStr, err := element(value)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(keyStr + ": " + valueStr)
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+func (s *StdMap) GoString() string {
+	return s.String()
+}
+
+// orderedMap keeps the keys in the insertion order, so the iteration and output are deterministic.
+type orderedMap struct {
+	keys   []any
+	values map[any]any
+}
+
+func (m *orderedMap) get(key any) (any, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// set adds the new key to the end, the existing key keeps its position.
+func (m *orderedMap) set(key, value any) {
+	if m.values == nil {
+		m.values = make(map[any]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// delete removes the key, reports whether it existed.
+func (m *orderedMap) delete(key any) bool {
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k any) bool { return k == key })
+	return true
+}
+
+func (m *orderedMap) len() int {
+	return len(m.keys)
+}
+
+var (
+	_ LoxInstance    = (*StdMap)(nil)
+	_ fmt.Stringer   = (*StdMap)(nil)
+	_ fmt.GoStringer = (*StdMap)(nil)
+)
@@ -0,0 +1,39 @@
+package interpreter
+
+import (
+	"os"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+)
+
+// fileBuiltins are the native functions defined in the globals, if the file access is granted.
+var fileBuiltins = map[string]Callable{
+	"read_file":  NativeFunction1(StdFnReadFile),
+	"write_file": NativeFunction2(StdFnWriteFile),
+}
+
+// StdFnReadFile returns the file contents as a string.
+func StdFnReadFile(interpeter *interpreter, path any) (any, error) {
+	name, ok := path.(string)
+	if !ok {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeString
+	}
+	contents, err := os.ReadFile(name) //nolint:gosec // the script was granted the file access
+	if err != nil {
+		return nil, err
+	}
+	return string(contents), nil
+}
+
+// StdFnWriteFile writes the string contents to the file, the existing file is truncated.
+func StdFnWriteFile(interpeter *interpreter, path, contents any) (any, error) {
+	name, ok := path.(string)
+	if !ok {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeString
+	}
+	data, ok := contents.(string)
+	if !ok {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeString
+	}
+	return nil, os.WriteFile(name, []byte(data), 0o644) //nolint:gosec // the script was granted the file access
+}
@@ -0,0 +1,183 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+)
+
+// StdFnJSONEncode encodes the value as the JSON string.
+// The arrays are encoded as JSON arrays; the maps and the instance fields as JSON objects.
+func StdFnJSONEncode(interpeter *interpreter, value any) (any, error) {
+	enc := &jsonEncoder{seen: make(map[any]bool)}
+	if err := enc.encode(value); err != nil {
+		return nil, err
+	}
+	return enc.sb.String(), nil
+}
+
+// StdFnJSONDecode decodes the JSON string, the objects are decoded as maps and the arrays as arrays.
+func StdFnJSONDecode(interpeter *interpreter, value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, loxerrors.ErrRuntimeArgumentMustBeString
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	decoded, err := decodeJSON(dec)
+	if err == nil {
+		if _, err = dec.Token(); errors.Is(err, io.EOF) {
+			return decoded, nil
+		} else if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, loxerrors.ErrRuntimeInvalidJSON(err.Error())
+}
+
+type jsonEncoder struct {
+	sb strings.Builder
+	// seen are the containers being encoded, to detect the cycles.
+	seen map[any]bool
+}
+
+func (e *jsonEncoder) encode(value any) error {
+	switch value := value.(type) {
+	case nil:
+		e.sb.WriteString("null")
+	case bool, string:
+		return e.marshal(value)
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return loxerrors.ErrRuntimeJSONUnsupportedValue(formatNumber(value))
+		}
+		return e.marshal(value)
+	case *StdArray:
+		return e.nested(value, func() error {
+			e.sb.WriteByte('[')
+			for index, element := range value.values {
+				if index > 0 {
+					e.sb.WriteByte(',')
+				}
+				if err := e.encode(element); err != nil {
+					return err
+				}
+			}
+			e.sb.WriteByte(']')
+			return nil
+		})
+	case *StdMap:
+		return e.nested(value, func() error {
+			return e.object(value.entries.keys, func(key any) any {
+				v, _ := value.entries.get(key)
+				return v
+			})
+		})
+	case *objectInstance:
+		keys := make([]any, 0, len(value.Fields))
+		for name := range value.Fields {
+			keys = append(keys, name)
+		}
+		slices.SortFunc(keys, func(a, b any) int { return strings.Compare(a.(string), b.(string)) })
+		return e.nested(value, func() error {
+			return e.object(keys, func(key any) any { return value.Fields[key.(string)] })
+		})
+	default:
+		return loxerrors.ErrRuntimeJSONUnsupportedValue(typeName(value))
+	}
+	return nil
+}
+
+// object encodes the JSON object, the number keys are encoded as strings.
+func (e *jsonEncoder) object(keys []any, get func(key any) any) error {
+	e.sb.WriteByte('{')
+	for index, key := range keys {
+		if index > 0 {
+			e.sb.WriteByte(',')
+		}
+		name := key
+		if n, ok := key.(float64); ok {
+			name = formatNumber(n)
+		}
+		if err := e.marshal(name); err != nil {
+			return err
+		}
+		e.sb.WriteByte(':')
+		if err := e.encode(get(key)); err != nil {
+			return err
+		}
+	}
+	e.sb.WriteByte('}')
+	return nil
+}
+
+// nested encodes the container, reports an error if it contains itself.
+func (e *jsonEncoder) nested(container any, encode func() error) error {
+	if e.seen[container] {
+		return loxerrors.ErrRuntimeJSONCyclicValue
+	}
+	e.seen[container] = true
+	defer delete(e.seen, container)
+	return encode()
+}
+
+func (e *jsonEncoder) marshal(value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e.sb.Write(b)
+	return nil
+}
+
+// decodeJSON decodes the next JSON value, the objects keep the keys in the source order.
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		var values []any
+		for dec.More() {
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return NewStdArray(values), nil
+	case json.Delim('{'):
+		m := NewStdMap()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.entries.set(key, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	// the scalars are decoded as nil, bool, float64 or string
+	return tok, nil
+}
@@ -0,0 +1,65 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/token"
+)
+
+// StdMath is the Math global with the read-only numeric constants.
+type StdMath struct{}
+
+// Get implements LoxInstance.
+func (s *StdMath) Get(name *token.Token) (any, error) {
+	switch name.Lexeme {
+	case "PI":
+		return math.Pi, nil
+	case "E":
+		return math.E, nil
+	}
+
+	return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeUndefinedProperty(name.Lexeme))
+}
+
+// Set implements LoxInstance.
+func (s *StdMath) Set(name *token.Token, value any) (any, error) {
+	return nil, loxerrors.NewRuntimeError(name, loxerrors.ErrRuntimeMathCantSetProperties)
+}
+
+func (s *StdMath) String() string {
+	return "Math"
+}
+
+func (s *StdMath) GoString() string {
+	return s.String()
+}
+
+var (
+	_ LoxInstance    = (*StdMath)(nil)
+	_ fmt.Stringer   = (*StdMath)(nil)
+	_ fmt.GoStringer = (*StdMath)(nil)
+)
+
+// StdFnRandom returns the pseudo-random number in [0, 1).
+func StdFnRandom(interpeter *interpreter) (any, error) {
+	if interpeter.random != nil {
+		return interpeter.random.Float64(), nil
+	}
+	return rand.Float64(), nil //nolint:gosec // not for the security sensitive use
+}
+
+// StdFnRandomInt returns the pseudo-random integer number in [0, n).
+func StdFnRandomInt(interpeter *interpreter, n any) (any, error) {
+	bound, ok := n.(float64)
+	if !ok || bound != math.Trunc(bound) || bound < 1 || bound >= math.MaxInt64 {
+		return nil, loxerrors.ErrRuntimeArgumentMustBePositiveInteger
+	}
+
+	if interpeter.random != nil {
+		return float64(interpeter.random.Int63n(int64(bound))), nil
+	}
+	return float64(rand.Int63n(int64(bound))), nil //nolint:gosec // not for the security sensitive use
+}
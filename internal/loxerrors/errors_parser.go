@@ -10,6 +10,8 @@ import (
 var (
 	ErrParseError                                 = errors.New("Parse error.")
 	ErrParseUnexpectedToken                       = errors.New("Expect expression.")
+	ErrParseRestParameterMustBeLast               = errors.New("Rest parameter must be last.")
+	ErrParseExpectedEndOfExpression               = errors.New("Expect end of expression.")
 	ErrParseUnexpectedVariableName                = errors.New("Expect variable name.")
 	ErrParseCantInitVarSelfReference              = errors.New("Can't read local variable in its own initializer.")
 	ErrParseCantDuplicateVariableDefinition       = errors.New("Already a variable with this name in this scope.")
@@ -38,6 +40,7 @@ var (
 	ErrParseTooManyArguments                      = errors.New("Can't have more than 255 arguments.")
 	ErrParseTooManyParameters                     = errors.New("Can't have more than 255 parameters.")
 	ErrParseLocalVariableNotUsed                  = errors.New("Local variable is not used.")
+	ErrParseLocalVariableShadowsOuter             = errors.New("Local variable shadows the variable in the enclosing scope.")
 	ErrParseExpectClassName                       = errors.New("Expect class name.")
 	ErrParseExpectSuperClassName                  = errors.New("Expect superclass name.")
 	ErrParseExpectLeftCurlyBeforeClassBody        = errors.New("Expect '{' before class body.")
@@ -50,6 +53,23 @@ var (
 	ErrParseCantUseSuperOutsideClass              = errors.New("Can't use 'super' outside of a class.")
 	ErrParseCantUseSuperInClassWithNoSuperclass   = errors.New("Can't use 'super' in a class with no superclass.")
 	ErrParseCantUseSuperInClassMethod             = errors.New("Can't use 'super' in a static class method.")
+	ErrParseExpectedRightBracketToken             = errors.New("Expect ']' after array elements.")
+	ErrParseExpectedColonAfterMapKey              = errors.New("Expect ':' after map key.")
+	ErrParseExpectedRightBraceAfterMapEntries     = errors.New("Expect '}' after map entries.")
+	ErrParseExpectedLeftBraceAfterTry             = errors.New("Expect '{' after 'try'.")
+	ErrParseExpectedCatchOrFinally                = errors.New("Expect 'catch' or 'finally' after try block.")
+	ErrParseExpectedLeftParenAfterCatch           = errors.New("Expect '(' after 'catch'.")
+	ErrParseExpectedCatchVariableName             = errors.New("Expect catch variable name.")
+	ErrParseExpectedRightParenAfterCatch          = errors.New("Expect ')' after catch variable.")
+	ErrParseExpectedLeftBraceAfterCatch           = errors.New("Expect '{' after catch clause.")
+	ErrParseExpectedLeftBraceAfterFinally         = errors.New("Expect '{' after 'finally'.")
+	ErrParseExpectedSemicolonTokenAfterThrow      = errors.New("Expect ';' after thrown value.")
+	ErrParseExpectedLoopAfterLabel                = errors.New("Expect 'while' or 'for' after label.")
+	ErrParseExpectedConstInitializer              = errors.New("Expect '=' after constant name.")
+	ErrParseExpressionTooDeep                     = errors.New("Expression nesting is too deep.")
+	ErrParseBlockTooDeep                          = errors.New("Block nesting is too deep.")
+	ErrParseTokensEmpty                           = errors.New("Tokens cannot be empty.")
+	ErrParseTokensMissingEOF                      = errors.New("Tokens must end with EOF.")
 )
 
 func ErrParseExpectedIdentifierKindError(kind string) error {
@@ -79,7 +99,12 @@ func (p *ParserError) Error() string {
 	if p.tok.Type != token.EOF {
 		where = fmt.Sprintf("at '%s'", p.tok.Lexeme)
 	}
-	return fmt.Sprintf("[line %d] Error %s: %v", p.tok.Line, where, p.cause)
+	return fmt.Sprintf("[line %d] Error %s%s: %v", p.tok.Line, where, token.ColumnSuffix(p.tok.Column), p.cause)
+}
+
+// Token returns the token the error is reported at.
+func (p *ParserError) Token() *token.Token {
+	return p.tok
 }
 
 func (p *ParserError) Unwrap() error {
@@ -90,3 +115,11 @@ var (
 	_ error           = (*ParserError)(nil)
 	_ unwrapInterface = (*ParserError)(nil)
 )
+
+func ErrParseUndefinedLabelError(label string) error {
+	return fmt.Errorf("Undefined label '%s'.", label)
+}
+
+func ErrParseDuplicateLabelError(label string) error {
+	return fmt.Errorf("Label '%s' is already defined.", label)
+}
@@ -3,51 +3,129 @@ package loxerrors
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/leonardinius/golox/internal/token"
 )
 
 var (
-	ErrRuntimeOperandMustBeNumber          = errors.New("Operand must be a number.")
-	ErrRuntimeOperandsMustBeNumbers        = errors.New("Operands must be numbers.")
-	ErrRuntimeOperandsMustNumbersOrStrings = errors.New("Operands must be two numbers or two strings.")
-	ErrRuntimeUndefinedVariable            = errors.New("Undefined variable")
-	ErrRuntimeCalleeMustBeCallable         = errors.New("Can only call functions and classes.")
-	ErrRuntimeOnlyInstancesHaveProperties  = errors.New("Only instances have properties.")
-	ErrRuntimeOnlyInstancesHaveFields      = errors.New("Only instances have fields.")
-	ErrRuntimeSuperClassMustBeClass        = errors.New("Superclass must be a class.")
-	ErrRuntimeArraysCantSetProperties      = errors.New("Can't set properties on arrays.")
-	ErrRuntimeArrayIndexOutOfRange         = errors.New("Array index out of range.")
-	ErrRuntimeArrayInvalidArrayIndex       = errors.New("Invalid array index, must be number.")
-	ErrRuntimeArrayInvalidArraySize        = errors.New("Invalid array size, must be number.")
+	ErrRuntimeOperandMustBeNumber                    = errors.New("Operand must be a number.")
+	ErrRuntimeOperandsMustBeNumbers                  = errors.New("Operands must be numbers.")
+	ErrRuntimeOperandsMustNumbersOrStrings           = errors.New("Operands must be two numbers or two strings.")
+	ErrRuntimeDivisionByZero                         = errors.New("Division by zero.")
+	ErrRuntimeUndefinedVariable                      = errors.New("Undefined variable")
+	ErrRuntimeCantAssignConstant                     = errors.New("Cannot assign to constant")
+	ErrRuntimeCalleeMustBeCallable                   = errors.New("Can only call functions and classes.")
+	ErrRuntimeOnlyInstancesHaveProperties            = errors.New("Only instances have properties.")
+	ErrRuntimeOnlyInstancesHaveFields                = errors.New("Only instances have fields.")
+	ErrRuntimeSuperClassMustBeClass                  = errors.New("Superclass must be a class.")
+	ErrRuntimeArraysCantSetProperties                = errors.New("Can't set properties on arrays.")
+	ErrRuntimeArrayIndexOutOfRange                   = errors.New("Array index out of range.")
+	ErrRuntimeArrayInvalidArrayIndex                 = errors.New("Invalid array index, must be an integer number.")
+	ErrRuntimeArrayInvalidArraySize                  = errors.New("Invalid array size, must be a non-negative integer number.")
+	ErrRuntimeArrayEmpty                             = errors.New("Can't pop from an empty array.")
+	ErrRuntimeArrayCanOnlyAppendArrays               = errors.New("Can only append arrays.")
+	ErrRuntimeMapsCantSetProperties                  = errors.New("Can't set properties on maps.")
+	ErrRuntimeMathCantSetProperties                  = errors.New("Can't set properties on Math.")
+	ErrRuntimeMapInvalidKey                          = errors.New("Invalid map key, must be number or string.")
+	ErrRuntimeForEachIterableMustBeArray             = errors.New("Can only iterate over arrays.")
+	ErrRuntimeTimeout                                = errors.New("Execution timed out.")
+	ErrRuntimeTypeCheckOperandMustBeClass            = errors.New("Right operand of 'is' must be a class.")
+	ErrRuntimeArgumentMustBeString                   = errors.New("Argument must be a string.")
+	ErrRuntimeArgumentMustBeNonNegativeNumber        = errors.New("Argument must be a non-negative number.")
+	ErrRuntimeArgumentMustBePositiveInteger          = errors.New("Argument must be a positive integer number.")
+	ErrRuntimeArgumentMustBeNumberOrString           = errors.New("Argument must be a number or a string.")
+	ErrRuntimeJSONCyclicValue                        = errors.New("Can't encode a cyclic structure as JSON.")
+	ErrRuntimeArgumentMustBeFunctionWithoutArguments = errors.New("Argument must be a function without arguments.")
+	ErrRuntimeArgumentMustBeFunction                 = errors.New("Argument must be a function.")
+	ErrRuntimeAssertionFailed                        = errors.New("Assertion failed.")
+	ErrRuntimeExitCodeMustBeInteger                  = errors.New("Exit code must be an integer between 0 and 255.")
+	ErrRuntimeArgumentMustBeCharacter                = errors.New("Argument must be a one character string.")
+	ErrRuntimeArgumentMustBeCodePoint                = errors.New("Argument must be a valid Unicode code point.")
 )
 
 func ErrRuntimeCalleeArityError(expectedArity, actualArity int) error {
 	return fmt.Errorf("Expected %d arguments but got %d.", expectedArity, actualArity)
 }
 
+func ErrRuntimeCalleeMinArityError(requiredArity, actualArity int) error {
+	return fmt.Errorf("Expected at least %d arguments but got %d.", requiredArity, actualArity)
+}
+
+func ErrRuntimeCalleeArityRangeError(minArity, maxArity, actualArity int) error {
+	return fmt.Errorf("Expected %d to %d arguments but got %d.", minArity, maxArity, actualArity)
+}
+
+func ErrRuntimeJSONUnsupportedValue(value string) error {
+	return fmt.Errorf("Can't encode %s as JSON.", value)
+}
+
+func ErrRuntimeInvalidJSON(message string) error {
+	return fmt.Errorf("Invalid JSON: %s.", message)
+}
+
 func ErrRuntimeUndefinedProperty(name string) error {
 	return fmt.Errorf("Undefined property '%s'.", name)
 }
 
 func NewRuntimeError(tok *token.Token, cause error) error {
-	return &RuntimeError{tok, cause}
+	return &RuntimeError{tok: tok, cause: cause}
+}
+
+// StackFrame is the single call frame of the runtime error stack trace.
+type StackFrame struct {
+	// Function is the name of the function, empty for the top level script.
+	Function string
+	// Tok is the position being executed in the frame.
+	Tok *token.Token
+}
+
+func (f StackFrame) String() string {
+	if f.Function == "" {
+		return fmt.Sprintf("[line %d] in script%s", f.Tok.Line, token.ColumnSuffix(f.Tok.Column))
+	}
+	return fmt.Sprintf("[line %d] in %s%s", f.Tok.Line, f.Function, token.ColumnSuffix(f.Tok.Column))
 }
 
 type RuntimeError struct {
 	tok   *token.Token
 	cause error
+	trace []StackFrame
 }
 
 // Error implements error.
 func (r *RuntimeError) Error() string {
-	return fmt.Sprintf("%v\n[line %d] in script", r.cause, r.tok.Line)
+	if len(r.trace) == 0 {
+		return fmt.Sprintf("%v\n%s", r.cause, StackFrame{Tok: r.tok})
+	}
+	var sb strings.Builder
+	fmt.Fprint(&sb, r.cause)
+	for _, frame := range r.trace {
+		sb.WriteString("\n")
+		sb.WriteString(frame.String())
+	}
+	return sb.String()
 }
 
 func (r *RuntimeError) Unwrap() error {
 	return r.cause
 }
 
+// Token returns the token the error is reported at.
+func (r *RuntimeError) Token() *token.Token {
+	return r.tok
+}
+
+// StackTrace returns the call frames, innermost first; nil if not captured.
+func (r *RuntimeError) StackTrace() []StackFrame {
+	return r.trace
+}
+
+// SetStackTrace records the call frames, innermost first.
+func (r *RuntimeError) SetStackTrace(trace []StackFrame) {
+	r.trace = trace
+}
+
 var (
 	_ error           = (*RuntimeError)(nil)
 	_ unwrapInterface = (*RuntimeError)(nil)
@@ -3,6 +3,8 @@ package loxerrors
 import (
 	"errors"
 	"fmt"
+
+	"github.com/leonardinius/golox/internal/token"
 )
 
 var (
@@ -10,20 +12,22 @@ var (
 	ErrScanUnexpectedCharacter = errors.New("Unexpected character.")
 	ErrScanUnterminatedString  = errors.New("Unterminated string.")
 	ErrScanUnterminatedComment = errors.New("Unterminated comment.")
+	ErrScanInvalidEncoding     = errors.New("Source is not valid UTF-8.")
 )
 
 type ScannerError struct {
-	line  int
-	cause error
+	line   int
+	column int
+	cause  error
 }
 
-func NewScanError(line int, cause error) error {
-	return &ScannerError{line, cause}
+func NewScanError(line, column int, cause error) error {
+	return &ScannerError{line, column, cause}
 }
 
 // Error implements error.
 func (s *ScannerError) Error() string {
-	return fmt.Sprintf("[line %d] Error: %v", s.line, s.cause)
+	return fmt.Sprintf("[line %d] Error%s: %v", s.line, token.ColumnSuffix(s.column), s.cause)
 }
 
 func (s *ScannerError) Unwrap() error {
@@ -8,8 +8,21 @@ import (
 type ErrReporter interface {
 	ReportPanic(err error)
 	ReportError(err error)
+	ReportWarning(err error)
 }
 
+// The severity prefixes of the standard reporter output, e.g. `FATAL [line 1] Error at 'x' (col 5): ...`.
+// The CLI reports with DefaultReportPanic and DefaultReportError instead, unprefixed as the .lox test suite expects;
+// the warnings keep the WARN prefix there too, see DefaultReportWarning.
+const (
+	// PrefixFatal is the fatal error, the parsing stopped at the declaration.
+	PrefixFatal = "FATAL"
+	// PrefixError is the recoverable error, the parsing went on.
+	PrefixError = "ERR"
+	// PrefixWarning is the problem that doesn't stop the program.
+	PrefixWarning = "WARN"
+)
+
 type errReporter struct {
 	w io.Writer
 }
@@ -19,13 +32,61 @@ func NewErrReporter(w io.Writer) *errReporter {
 }
 
 // ReportPanic implements ErrReporter.
+// The fatal errors are prefixed with FATAL, to tell them from the recoverable ones.
 func (e *errReporter) ReportPanic(err error) {
-	DefaultReportPanic(e.w, err)
+	e.report(PrefixFatal, err)
 }
 
 // ReportError implements ErrReporter.
 func (e *errReporter) ReportError(err error) {
-	DefaultReportError(e.w, err)
+	e.report(PrefixError, err)
+}
+
+// ReportWarning implements ErrReporter.
+func (e *errReporter) ReportWarning(err error) {
+	e.report(PrefixWarning, err)
+}
+
+func (e *errReporter) report(prefix string, err error) {
+	fmt.Fprintf(e.w, "%s %v\n", prefix, err)
+}
+
+// CollectingReporter keeps the reported errors, e.g. for the tests to assert on the structured errors.
+type CollectingReporter struct {
+	Panics   []error
+	Errors   []error
+	Warnings []error
+	// all the reported errors in the reporting order.
+	all []error
+}
+
+// NewCollectingReporter returns the reporter with no errors collected yet.
+func NewCollectingReporter() *CollectingReporter {
+	return &CollectingReporter{}
+}
+
+// ReportPanic implements ErrReporter.
+func (c *CollectingReporter) ReportPanic(err error) {
+	c.Panics = append(c.Panics, err)
+	c.all = append(c.all, err)
+}
+
+// ReportError implements ErrReporter.
+func (c *CollectingReporter) ReportError(err error) {
+	c.Errors = append(c.Errors, err)
+	c.all = append(c.all, err)
+}
+
+// ReportWarning implements ErrReporter.
+// The warnings are kept separately from the errors.
+func (c *CollectingReporter) ReportWarning(err error) {
+	c.Warnings = append(c.Warnings, err)
+	c.all = append(c.all, err)
+}
+
+// All returns all the reported errors in the reporting order.
+func (c *CollectingReporter) All() []error {
+	return c.all
 }
 
 // DefaultReportPanic is the default implementation of ErrReporter.ReportPanic.
@@ -38,4 +99,13 @@ func DefaultReportError(w io.Writer, err error) {
 	fmt.Fprintf(w, "%v\n", err)
 }
 
-var _ ErrReporter = (*errReporter)(nil)
+// DefaultReportWarning is the default implementation of ErrReporter.ReportWarning.
+// The warning is prefixed with WARN, to tell it from the errors, as it doesn't fail the script.
+func DefaultReportWarning(w io.Writer, err error) {
+	fmt.Fprintf(w, "%s %v\n", PrefixWarning, err)
+}
+
+var (
+	_ ErrReporter = (*errReporter)(nil)
+	_ ErrReporter = (*CollectingReporter)(nil)
+)
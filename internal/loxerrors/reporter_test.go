@@ -0,0 +1,62 @@
+package loxerrors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+)
+
+func TestErrReporterPrefixes(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("[line 1] Error at '=' (col 5): Expect variable name.")
+	testcases := []struct {
+		name     string
+		report   func(w *strings.Builder)
+		reported string
+	}{
+		{
+			name:     "fatal",
+			report:   func(w *strings.Builder) { loxerrors.NewErrReporter(w).ReportPanic(err) },
+			reported: "FATAL [line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+		{
+			name:     "error",
+			report:   func(w *strings.Builder) { loxerrors.NewErrReporter(w).ReportError(err) },
+			reported: "ERR [line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+		{
+			name:     "warning",
+			report:   func(w *strings.Builder) { loxerrors.NewErrReporter(w).ReportWarning(err) },
+			reported: "WARN [line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+		{
+			name:     "default panic",
+			report:   func(w *strings.Builder) { loxerrors.DefaultReportPanic(w, err) },
+			reported: "[line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+		{
+			name:     "default error",
+			report:   func(w *strings.Builder) { loxerrors.DefaultReportError(w, err) },
+			reported: "[line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+		{
+			name:     "default warning",
+			report:   func(w *strings.Builder) { loxerrors.DefaultReportWarning(w, err) },
+			reported: "WARN [line 1] Error at '=' (col 5): Expect variable name.\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reported := new(strings.Builder)
+			tc.report(reported)
+			assert.Equal(t, tc.reported, reported.String())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import "github.com/leonardinius/golox/internal/token"
 
 // ExprVisitor is the interface that wraps the Visit method.
 type ExprVisitor interface {
+	VisitExprArray(exprArray *ExprArray) (any, error)
 	VisitExprAssign(exprAssign *ExprAssign) (any, error)
 	VisitExprBinary(exprBinary *ExprBinary) (any, error)
 	VisitExprCall(exprCall *ExprCall) (any, error)
@@ -14,9 +15,12 @@ type ExprVisitor interface {
 	VisitExprGrouping(exprGrouping *ExprGrouping) (any, error)
 	VisitExprLiteral(exprLiteral *ExprLiteral) (any, error)
 	VisitExprLogical(exprLogical *ExprLogical) (any, error)
+	VisitExprMap(exprMap *ExprMap) (any, error)
+	VisitExprPostfix(exprPostfix *ExprPostfix) (any, error)
 	VisitExprSet(exprSet *ExprSet) (any, error)
 	VisitExprSuper(exprSuper *ExprSuper) (any, error)
 	VisitExprThis(exprThis *ExprThis) (any, error)
+	VisitExprTypeCheck(exprTypeCheck *ExprTypeCheck) (any, error)
 	VisitExprUnary(exprUnary *ExprUnary) (any, error)
 	VisitExprVariable(exprVariable *ExprVariable) (any, error)
 }
@@ -25,6 +29,16 @@ type Expr interface {
 	Accept(v ExprVisitor) (any, error)
 }
 
+type ExprArray struct {
+	Elements []Expr
+}
+
+var _ Expr = (*ExprArray)(nil)
+
+func (e *ExprArray) Accept(v ExprVisitor) (any, error) {
+	return v.VisitExprArray(e)
+}
+
 type ExprAssign struct {
 	Name  *token.Token
 	Value Expr
@@ -61,8 +75,10 @@ func (e *ExprCall) Accept(v ExprVisitor) (any, error) {
 }
 
 type ExprFunction struct {
-	Parameters []*token.Token
-	Body       []Stmt
+	Parameters   []*token.Token
+	Body         []Stmt
+	IsVariadic   bool
+	InferredName *token.Token
 }
 
 var _ Expr = (*ExprFunction)(nil)
@@ -94,6 +110,7 @@ func (e *ExprGrouping) Accept(v ExprVisitor) (any, error) {
 
 type ExprLiteral struct {
 	Value any
+	Token *token.Token
 }
 
 var _ Expr = (*ExprLiteral)(nil)
@@ -114,6 +131,29 @@ func (e *ExprLogical) Accept(v ExprVisitor) (any, error) {
 	return v.VisitExprLogical(e)
 }
 
+type ExprMap struct {
+	Brace  *token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+var _ Expr = (*ExprMap)(nil)
+
+func (e *ExprMap) Accept(v ExprVisitor) (any, error) {
+	return v.VisitExprMap(e)
+}
+
+type ExprPostfix struct {
+	Target   Expr
+	Operator *token.Token
+}
+
+var _ Expr = (*ExprPostfix)(nil)
+
+func (e *ExprPostfix) Accept(v ExprVisitor) (any, error) {
+	return v.VisitExprPostfix(e)
+}
+
 type ExprSet struct {
 	Instance Expr
 	Name     *token.Token
@@ -147,6 +187,18 @@ func (e *ExprThis) Accept(v ExprVisitor) (any, error) {
 	return v.VisitExprThis(e)
 }
 
+type ExprTypeCheck struct {
+	Instance Expr
+	Keyword  *token.Token
+	Class    Expr
+}
+
+var _ Expr = (*ExprTypeCheck)(nil)
+
+func (e *ExprTypeCheck) Accept(v ExprVisitor) (any, error) {
+	return v.VisitExprTypeCheck(e)
+}
+
 type ExprUnary struct {
 	Operator *token.Token
 	Right    Expr
@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leonardinius/golox/internal/token"
+)
+
+// AstPrinter renders the syntax tree as S-expressions, e.g. `(var a (+ 1 2))`.
+type AstPrinter struct{}
+
+func NewAstPrinter() *AstPrinter {
+	return &AstPrinter{}
+}
+
+// Print renders the statements, one top level statement per line.
+func (p *AstPrinter) Print(stmts []Stmt) string {
+	var sb strings.Builder
+	for _, stmt := range stmts {
+		sb.WriteString(p.PrintStmt(stmt))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// PrintStmt renders the single statement.
+func (p *AstPrinter) PrintStmt(stmt Stmt) string {
+	if stmt == nil {
+		return "_"
+	}
+	value, _ := stmt.Accept(p)
+	return value.(string)
+}
+
+// PrintExpr renders the single expression.
+func (p *AstPrinter) PrintExpr(expr Expr) string {
+	if expr == nil {
+		return "_"
+	}
+	value, _ := expr.Accept(p)
+	return value.(string)
+}
+
+// VisitExprArray implements ExprVisitor.
+func (p *AstPrinter) VisitExprArray(exprArray *ExprArray) (any, error) {
+	return p.parenthesize("array", exprArray.Elements), nil
+}
+
+// VisitExprAssign implements ExprVisitor.
+func (p *AstPrinter) VisitExprAssign(exprAssign *ExprAssign) (any, error) {
+	return p.parenthesize("=", exprAssign.Name, exprAssign.Value), nil
+}
+
+// VisitExprBinary implements ExprVisitor.
+func (p *AstPrinter) VisitExprBinary(exprBinary *ExprBinary) (any, error) {
+	return p.parenthesize(exprBinary.Operator.Lexeme, exprBinary.Left, exprBinary.Right), nil
+}
+
+// VisitExprCall implements ExprVisitor.
+func (p *AstPrinter) VisitExprCall(exprCall *ExprCall) (any, error) {
+	return p.parenthesize("call", exprCall.Callee, exprCall.Arguments), nil
+}
+
+// VisitExprFunction implements ExprVisitor.
+func (p *AstPrinter) VisitExprFunction(exprFunction *ExprFunction) (any, error) {
+	return p.parenthesize("fun", p.params(exprFunction), exprFunction.Body), nil
+}
+
+// VisitExprGet implements ExprVisitor.
+func (p *AstPrinter) VisitExprGet(exprGet *ExprGet) (any, error) {
+	return p.parenthesize(".", exprGet.Instance, exprGet.Name), nil
+}
+
+// VisitExprGrouping implements ExprVisitor.
+func (p *AstPrinter) VisitExprGrouping(exprGrouping *ExprGrouping) (any, error) {
+	return p.parenthesize("group", exprGrouping.Expression), nil
+}
+
+// VisitExprLiteral implements ExprVisitor.
+func (p *AstPrinter) VisitExprLiteral(exprLiteral *ExprLiteral) (any, error) {
+	switch value := exprLiteral.Value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return fmt.Sprintf("%q", value), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// VisitExprLogical implements ExprVisitor.
+func (p *AstPrinter) VisitExprLogical(exprLogical *ExprLogical) (any, error) {
+	return p.parenthesize(exprLogical.Operator.Lexeme, exprLogical.Left, exprLogical.Right), nil
+}
+
+// VisitExprMap implements ExprVisitor.
+func (p *AstPrinter) VisitExprMap(exprMap *ExprMap) (any, error) {
+	entries := make([]any, 0, len(exprMap.Keys))
+	for index, key := range exprMap.Keys {
+		entries = append(entries, p.parenthesize(":", key, exprMap.Values[index]))
+	}
+	return p.parenthesize("map", entries...), nil
+}
+
+// VisitExprSet implements ExprVisitor.
+func (p *AstPrinter) VisitExprSet(exprSet *ExprSet) (any, error) {
+	return p.parenthesize("=", p.parenthesize(".", exprSet.Instance, exprSet.Name), exprSet.Value), nil
+}
+
+// VisitExprSuper implements ExprVisitor.
+func (p *AstPrinter) VisitExprSuper(exprSuper *ExprSuper) (any, error) {
+	return p.parenthesize("super", exprSuper.Method), nil
+}
+
+// VisitExprThis implements ExprVisitor.
+func (p *AstPrinter) VisitExprThis(exprThis *ExprThis) (any, error) {
+	return "this", nil
+}
+
+// VisitExprTypeCheck implements ExprVisitor.
+func (p *AstPrinter) VisitExprTypeCheck(exprTypeCheck *ExprTypeCheck) (any, error) {
+	return p.parenthesize("is", exprTypeCheck.Instance, exprTypeCheck.Class), nil
+}
+
+// VisitExprPostfix implements ExprVisitor.
+func (p *AstPrinter) VisitExprPostfix(exprPostfix *ExprPostfix) (any, error) {
+	return p.parenthesize("post"+exprPostfix.Operator.Lexeme, exprPostfix.Target), nil
+}
+
+// VisitExprUnary implements ExprVisitor.
+func (p *AstPrinter) VisitExprUnary(exprUnary *ExprUnary) (any, error) {
+	return p.parenthesize(exprUnary.Operator.Lexeme, exprUnary.Right), nil
+}
+
+// VisitExprVariable implements ExprVisitor.
+func (p *AstPrinter) VisitExprVariable(exprVariable *ExprVariable) (any, error) {
+	return exprVariable.Name.Lexeme, nil
+}
+
+// VisitStmtBlock implements StmtVisitor.
+func (p *AstPrinter) VisitStmtBlock(stmtBlock *StmtBlock) (any, error) {
+	return p.parenthesize("block", stmtBlock.Statements), nil
+}
+
+// VisitStmtClass implements StmtVisitor.
+func (p *AstPrinter) VisitStmtClass(stmtClass *StmtClass) (any, error) {
+	parts := []any{stmtClass.Name}
+	if stmtClass.SuperClass != nil {
+		parts = append(parts, "<", stmtClass.SuperClass)
+	}
+	for _, field := range stmtClass.Fields {
+		parts = append(parts, field)
+	}
+	for _, method := range stmtClass.Methods {
+		parts = append(parts, method)
+	}
+	for _, method := range stmtClass.ClassMethods {
+		parts = append(parts, p.parenthesize("class", method))
+	}
+	return p.parenthesize("class", parts...), nil
+}
+
+// VisitStmtExpression implements StmtVisitor.
+func (p *AstPrinter) VisitStmtExpression(stmtExpression *StmtExpression) (any, error) {
+	return p.parenthesize(";", stmtExpression.Expression), nil
+}
+
+// VisitStmtFunction implements StmtVisitor.
+func (p *AstPrinter) VisitStmtFunction(stmtFunction *StmtFunction) (any, error) {
+	if stmtFunction.IsGetter {
+		return p.parenthesize("get", stmtFunction.Name, stmtFunction.Fn.Body), nil
+	}
+	return p.parenthesize("fun", stmtFunction.Name, p.params(stmtFunction.Fn), stmtFunction.Fn.Body), nil
+}
+
+// VisitStmtIf implements StmtVisitor.
+func (p *AstPrinter) VisitStmtIf(stmtIf *StmtIf) (any, error) {
+	if stmtIf.ElseBranch == nil {
+		return p.parenthesize("if", stmtIf.Condition, stmtIf.ThenBranch), nil
+	}
+	return p.parenthesize("if", stmtIf.Condition, stmtIf.ThenBranch, stmtIf.ElseBranch), nil
+}
+
+// VisitStmtPrint implements StmtVisitor.
+func (p *AstPrinter) VisitStmtPrint(stmtPrint *StmtPrint) (any, error) {
+	return p.parenthesize("print", stmtPrint.Expressions), nil
+}
+
+// VisitStmtReturn implements StmtVisitor.
+func (p *AstPrinter) VisitStmtReturn(stmtReturn *StmtReturn) (any, error) {
+	if stmtReturn.Value == nil {
+		return "(return)", nil
+	}
+	return p.parenthesize("return", stmtReturn.Value), nil
+}
+
+// VisitStmtVar implements StmtVisitor.
+func (p *AstPrinter) VisitStmtVar(stmtVar *StmtVar) (any, error) {
+	keyword := "var"
+	if stmtVar.IsConst {
+		keyword = "const"
+	}
+	if stmtVar.Initializer == nil {
+		return p.parenthesize(keyword, stmtVar.Name), nil
+	}
+	return p.parenthesize(keyword, stmtVar.Name, stmtVar.Initializer), nil
+}
+
+// VisitStmtWhile implements StmtVisitor.
+func (p *AstPrinter) VisitStmtWhile(stmtWhile *StmtWhile) (any, error) {
+	if stmtWhile.Label != nil {
+		return p.parenthesize("while", stmtWhile.Label, stmtWhile.Condition, stmtWhile.Body), nil
+	}
+	return p.parenthesize("while", stmtWhile.Condition, stmtWhile.Body), nil
+}
+
+// VisitStmtFor implements StmtVisitor.
+func (p *AstPrinter) VisitStmtFor(stmtFor *StmtFor) (any, error) {
+	if stmtFor.Label != nil {
+		return p.parenthesize("for", stmtFor.Label,
+			p.PrintStmt(stmtFor.Initializer),
+			p.PrintExpr(stmtFor.Condition),
+			p.PrintExpr(stmtFor.Increment),
+			stmtFor.Body,
+		), nil
+	}
+	return p.parenthesize("for",
+		p.PrintStmt(stmtFor.Initializer),
+		p.PrintExpr(stmtFor.Condition),
+		p.PrintExpr(stmtFor.Increment),
+		stmtFor.Body,
+	), nil
+}
+
+// VisitStmtForEach implements StmtVisitor.
+func (p *AstPrinter) VisitStmtForEach(stmtForEach *StmtForEach) (any, error) {
+	if stmtForEach.Label != nil {
+		return p.parenthesize("for", stmtForEach.Label, stmtForEach.Name, "in", stmtForEach.Iterable, stmtForEach.Body), nil
+	}
+	return p.parenthesize("for", stmtForEach.Name, "in", stmtForEach.Iterable, stmtForEach.Body), nil
+}
+
+// VisitStmtTry implements StmtVisitor.
+func (p *AstPrinter) VisitStmtTry(stmtTry *StmtTry) (any, error) {
+	parts := []any{p.parenthesize("block", stmtTry.Body)}
+	if stmtTry.CatchName != nil {
+		parts = append(parts, p.parenthesize("catch", stmtTry.CatchName, stmtTry.CatchBody))
+	}
+	if stmtTry.FinallyBody != nil {
+		parts = append(parts, p.parenthesize("finally", stmtTry.FinallyBody))
+	}
+	return p.parenthesize("try", parts...), nil
+}
+
+// VisitStmtThrow implements StmtVisitor.
+func (p *AstPrinter) VisitStmtThrow(stmtThrow *StmtThrow) (any, error) {
+	return p.parenthesize("throw", stmtThrow.Value), nil
+}
+
+// VisitStmtBreak implements StmtVisitor.
+func (p *AstPrinter) VisitStmtBreak(stmtBreak *StmtBreak) (any, error) {
+	if stmtBreak.Label != nil {
+		return p.parenthesize("break", stmtBreak.Label), nil
+	}
+	return "(break)", nil
+}
+
+// VisitStmtContinue implements StmtVisitor.
+func (p *AstPrinter) VisitStmtContinue(stmtContinue *StmtContinue) (any, error) {
+	if stmtContinue.Label != nil {
+		return p.parenthesize("continue", stmtContinue.Label), nil
+	}
+	return "(continue)", nil
+}
+
+// VisitStmtEmpty implements StmtVisitor.
+func (p *AstPrinter) VisitStmtEmpty(stmtEmpty *StmtEmpty) (any, error) {
+	return "(empty)", nil
+}
+
+func (p *AstPrinter) params(fn *ExprFunction) string {
+	return "(" + strings.Join(paramNames(fn), " ") + ")"
+}
+
+// parenthesize renders `(name parts...)`, the statement and expression lists are spread.
+func (p *AstPrinter) parenthesize(name string, parts ...any) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	sb.WriteString(name)
+	for _, part := range parts {
+		switch part := part.(type) {
+		case []Expr:
+			for _, expr := range part {
+				sb.WriteString(" " + p.PrintExpr(expr))
+			}
+		case []Stmt:
+			for _, stmt := range part {
+				sb.WriteString(" " + p.PrintStmt(stmt))
+			}
+		case Expr:
+			sb.WriteString(" " + p.PrintExpr(part))
+		case Stmt:
+			sb.WriteString(" " + p.PrintStmt(part))
+		case *token.Token:
+			sb.WriteString(" " + part.Lexeme)
+		default:
+			sb.WriteString(fmt.Sprint(" ", part))
+		}
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+var (
+	_ ExprVisitor = (*AstPrinter)(nil)
+	_ StmtVisitor = (*AstPrinter)(nil)
+)
@@ -0,0 +1,74 @@
+package parser_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+func TestAstPrinter(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"var", `var a = 1 + 2;`, `(var a (+ 1 2))`},
+		{"var nil", `var a;`, `(var a)`},
+		{"literals", `print nil; print true; print 2.5; print "s";`, "(print nil)\n(print true)\n(print 2.5)\n(print \"s\")"},
+		{"expression", `-(1 * 2) >= 3 / 4;`, `(; (>= (- (group (* 1 2))) (/ 3 4)))`},
+		{"logical", `a and b or !c;`, `(; (or (and a b) (! c)))`},
+		{"assign", `a = b = 1;`, `(; (= a (= b 1)))`},
+		{"array", `[1, [2]];`, `(; (array 1 (array 2)))`},
+		{"call get set", `a.b(1, 2).c = d;`, `(; (= (. (call (. a b) 1 2) c) d))`},
+		{"is", `a is B;`, `(; (is a B))`},
+		{"postfix", `a.b++ + c-- * --d;`, `(; (+ (post++ (. a b)) (* (post-- c) (- (- d)))))`},
+		{"minus negated operand", `5--3; a--1; a - -b;`, "(; (- 5 (- 3)))\n(; (- a (- 1)))\n(; (- a (- b)))"},
+		{"postfix then minus", `a-- -b; a---b; a++ -1;`, "(; (- (post-- a) b))\n(; (- (post-- a) b))\n(; (- (post++ a) 1))"},
+		{"power", `-a ** b ** c * d;`, `(; (* (- (** a (** b c))) d))`},
+		{"empty", `while (a);`, `(while a (empty))`},
+		{"const", `const a = 1;`, `(const a 1)`},
+		{"xor", `a or b xor c and d;`, `(; (xor (or a b) (and c d)))`},
+		{"print many", `print 1, a + b, "s";`, `(print 1 (+ a b) "s")`},
+		{"block", `{ var a = 1; print a; }`, `(block (var a 1) (print a))`},
+		{"if", `if (a) print 1; else print 2;`, `(if a (print 1) (print 2))`},
+		{"if without else", `if (a) print 1;`, `(if a (print 1))`},
+		{"while", `while (a) { break; continue; }`, `(while a (block (break) (continue)))`},
+		{"for", `for (var i = 0; i < 1; i = i + 1) print i;`, `(for (var i 0) (< i 1) (= i (+ i 1)) (print i))`},
+		{"for empty", `for (;;) break;`, `(for _ true _ (break))`},
+		{"labeled", `outer: while (a) for (var x in b) continue outer;`, `(while outer a (for x in b (continue outer)))`},
+		{"variadic", `fun f(a, ...rest) { }`, `(fun f (a ...rest))`},
+		{"map", `var m = {"a": 1, 2: [],};`, `(var m (map (: "a" 1) (: 2 (array))))`},
+		{"for each", `for (var x in [1, 2]) print x;`, `(for x in (array 1 2) (print x))`},
+		{"function", `fun f(a, b) { return a + b; }`, `(fun f (a b) (return (+ a b)))`},
+		{"function return nil", `fun f() { return; }`, `(fun f () (return))`},
+		{"anonymous function", `var f = fun (a) { print a; };`, `(var f (fun (a) (print a)))`},
+		{
+			"class",
+			`class B < A { var x = 1; init() { this.y = super.m(); } area { return 0; } class make() { return B(); } }`,
+			`(class B < A (var x 1) (fun init () (; (= (. this y) (call (super m))))) (get area (return 0)) (class (fun make () (return (call B)))))`,
+		},
+		{"try", `try { throw 1; } catch (e) { print e; } finally { print 2; }`, `(try (block (throw 1)) (catch e (print e)) (finally (print 2)))`},
+		{"try finally", `try { } finally { }`, `(try (block) (finally))`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			tokens, err := scanner.NewScanner(tc.input, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected+"\n", parser.NewAstPrinter().Print(stmts))
+		})
+	}
+}
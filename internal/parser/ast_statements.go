@@ -16,8 +16,12 @@ type StmtVisitor interface {
 	VisitStmtVar(stmtVar *StmtVar) (any, error)
 	VisitStmtWhile(stmtWhile *StmtWhile) (any, error)
 	VisitStmtFor(stmtFor *StmtFor) (any, error)
+	VisitStmtForEach(stmtForEach *StmtForEach) (any, error)
+	VisitStmtTry(stmtTry *StmtTry) (any, error)
+	VisitStmtThrow(stmtThrow *StmtThrow) (any, error)
 	VisitStmtBreak(stmtBreak *StmtBreak) (any, error)
 	VisitStmtContinue(stmtContinue *StmtContinue) (any, error)
+	VisitStmtEmpty(stmtEmpty *StmtEmpty) (any, error)
 }
 
 type Stmt interface {
@@ -37,6 +41,7 @@ func (e *StmtBlock) Accept(v StmtVisitor) (any, error) {
 type StmtClass struct {
 	Name         *token.Token
 	SuperClass   *ExprVariable
+	Fields       []*StmtVar
 	Methods      []*StmtFunction
 	ClassMethods []*StmtFunction
 }
@@ -58,8 +63,9 @@ func (e *StmtExpression) Accept(v StmtVisitor) (any, error) {
 }
 
 type StmtFunction struct {
-	Name *token.Token
-	Fn   *ExprFunction
+	Name     *token.Token
+	Fn       *ExprFunction
+	IsGetter bool
 }
 
 var _ Stmt = (*StmtFunction)(nil)
@@ -81,7 +87,8 @@ func (e *StmtIf) Accept(v StmtVisitor) (any, error) {
 }
 
 type StmtPrint struct {
-	Expression Expr
+	Keyword     *token.Token
+	Expressions []Expr
 }
 
 var _ Stmt = (*StmtPrint)(nil)
@@ -104,6 +111,7 @@ func (e *StmtReturn) Accept(v StmtVisitor) (any, error) {
 type StmtVar struct {
 	Name        *token.Token
 	Initializer Expr
+	IsConst     bool
 }
 
 var _ Stmt = (*StmtVar)(nil)
@@ -115,6 +123,7 @@ func (e *StmtVar) Accept(v StmtVisitor) (any, error) {
 type StmtWhile struct {
 	Condition Expr
 	Body      Stmt
+	Label     *token.Token
 }
 
 var _ Stmt = (*StmtWhile)(nil)
@@ -128,6 +137,7 @@ type StmtFor struct {
 	Condition   Expr
 	Increment   Expr
 	Body        Stmt
+	Label       *token.Token
 }
 
 var _ Stmt = (*StmtFor)(nil)
@@ -136,7 +146,47 @@ func (e *StmtFor) Accept(v StmtVisitor) (any, error) {
 	return v.VisitStmtFor(e)
 }
 
+type StmtForEach struct {
+	Name     *token.Token
+	In       *token.Token
+	Iterable Expr
+	Body     Stmt
+	Label    *token.Token
+}
+
+var _ Stmt = (*StmtForEach)(nil)
+
+func (e *StmtForEach) Accept(v StmtVisitor) (any, error) {
+	return v.VisitStmtForEach(e)
+}
+
+type StmtTry struct {
+	Body        []Stmt
+	CatchName   *token.Token
+	CatchBody   []Stmt
+	FinallyBody []Stmt
+}
+
+var _ Stmt = (*StmtTry)(nil)
+
+func (e *StmtTry) Accept(v StmtVisitor) (any, error) {
+	return v.VisitStmtTry(e)
+}
+
+type StmtThrow struct {
+	Keyword *token.Token
+	Value   Expr
+}
+
+var _ Stmt = (*StmtThrow)(nil)
+
+func (e *StmtThrow) Accept(v StmtVisitor) (any, error) {
+	return v.VisitStmtThrow(e)
+}
+
 type StmtBreak struct {
+	Keyword *token.Token
+	Label   *token.Token
 }
 
 var _ Stmt = (*StmtBreak)(nil)
@@ -146,6 +196,8 @@ func (e *StmtBreak) Accept(v StmtVisitor) (any, error) {
 }
 
 type StmtContinue struct {
+	Keyword *token.Token
+	Label   *token.Token
 }
 
 var _ Stmt = (*StmtContinue)(nil)
@@ -153,3 +205,13 @@ var _ Stmt = (*StmtContinue)(nil)
 func (e *StmtContinue) Accept(v StmtVisitor) (any, error) {
 	return v.VisitStmtContinue(e)
 }
+
+type StmtEmpty struct {
+	Semicolon *token.Token
+}
+
+var _ Stmt = (*StmtEmpty)(nil)
+
+func (e *StmtEmpty) Accept(v StmtVisitor) (any, error) {
+	return v.VisitStmtEmpty(e)
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"slices"
+
+	"github.com/leonardinius/golox/internal/token"
+)
+
+// Comments are the source comments associated with the parsed statements, see scanner.WithComments.
+type Comments struct {
+	// leading comments precede the statement, each on its own line.
+	leading map[Stmt][]token.Token
+	// inline comment follows the statement on the same line.
+	inline map[Stmt]token.Token
+	// closing comments precede the closing brace of the block (*[]Stmt) or the class body (*StmtClass).
+	closing map[any][]token.Token
+	// trailing comments follow the last statement of the script.
+	trailing []token.Token
+}
+
+func newComments() *Comments {
+	return &Comments{
+		leading: make(map[Stmt][]token.Token),
+		inline:  make(map[Stmt]token.Token),
+		closing: make(map[any][]token.Token),
+	}
+}
+
+// Leading returns the comments preceding the statement.
+func (c *Comments) Leading(stmt Stmt) []token.Token {
+	if c == nil {
+		return nil
+	}
+	return c.leading[stmt]
+}
+
+// Inline returns the comment on the statement last line, if any.
+func (c *Comments) Inline(stmt Stmt) (token.Token, bool) {
+	if c == nil {
+		return token.Token{}, false
+	}
+	comment, ok := c.inline[stmt]
+	return comment, ok
+}
+
+// Closing returns the comments before the closing brace of the block statements (*[]Stmt), e.g. &StmtBlock.Statements,
+// or of the class body (*StmtClass). The comments of the empty block are kept there too.
+func (c *Comments) Closing(block any) []token.Token {
+	if c == nil {
+		return nil
+	}
+	return c.closing[block]
+}
+
+// Trailing returns the comments following the last statement of the script.
+func (c *Comments) Trailing() []token.Token {
+	if c == nil {
+		return nil
+	}
+	return c.trailing
+}
+
+func addComments[K comparable](comments map[K][]token.Token, key K, tokens []token.Token) {
+	if len(tokens) > 0 {
+		comments[key] = append(comments[key], tokens...)
+	}
+}
+
+// splitComments returns the tokens without comments, and the comments keyed by the index of the token they precede.
+func splitComments(tokens []token.Token) (code []token.Token, comments map[int][]token.Token) {
+	if !slices.ContainsFunc(tokens, func(tok token.Token) bool { return tok.Type == token.COMMENT }) {
+		return tokens, nil
+	}
+
+	code = make([]token.Token, 0, len(tokens))
+	comments = make(map[int][]token.Token)
+	for _, tok := range tokens {
+		if tok.Type == token.COMMENT {
+			comments[len(code)] = append(comments[len(code)], tok)
+		} else {
+			code = append(code, tok)
+		}
+	}
+	return code, comments
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"reflect"
+
+	"github.com/leonardinius/golox/internal/token"
+)
+
+var tokenPtrType = reflect.TypeOf((*token.Token)(nil))
+
+// Equal reports whether the statements are structurally equal, e.g. parsed from the differently formatted sources.
+// The tokens are compared by the type and the literal value (the lexeme if there is none), the positions are ignored.
+func Equal(a, b Stmt) bool {
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// EqualExpr is Equal for the expressions.
+func EqualExpr(a, b Expr) bool {
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// EqualStmts is Equal for the statement lists, e.g. the parsed programs.
+func EqualStmts(a, b []Stmt) bool {
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func equalValues(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	if a.Type() == tokenPtrType {
+		return equalTokens(a.Interface().(*token.Token), b.Interface().(*token.Token))
+	}
+
+	switch a.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := range a.Len() {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
+
+// equalTokens compares the tokens ignoring the positions; the numbers are compared by value, e.g. 1.50 equals 1.5.
+func equalTokens(a, b *token.Token) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	if a.Literal != nil || b.Literal != nil {
+		return a.Literal == b.Literal
+	}
+	return a.Lexeme == b.Lexeme
+}
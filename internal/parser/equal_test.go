@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonardinius/golox/internal/parser"
+)
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"spacing", `print 1+2*3;`, "print 1 +\n  2 * 3;", true},
+		{"comments", `var a = "s"; // a`, `/* a */ var a = "s";`, true},
+		{"number format", `print 1.50;`, `print 1.5;`, true},
+		{"functions", `fun f(a,b){return a;}`, "fun f(a, b) {\n  return a;\n}", true},
+		{"classes", `class A<B{init(x){this.x=x;} m(){return super.m();}}`, "class A < B {\n  init(x) { this.x = x; }\n  m() { return super.m(); }\n}", true},
+		{"loops", `for(var i=0;i<2;i=i+1)print i; for(;;)break;`, `for (var i = 0; i < 2; i = i + 1) print i; for (; ; ) break;`, true},
+		{"different operator", `print 1 + 2;`, `print 1 - 2;`, false},
+		{"different literal", `print 1;`, `print 2;`, false},
+		{"different literal type", `print 1;`, `print "1";`, false},
+		{"different name", `var a = 1;`, `var b = 1;`, false},
+		{"different grouping", `print (1 + 2) * 3;`, `print 1 + 2 * 3;`, false},
+		{"missing else", `if (a) print 1; else print 2;`, `if (a) print 1;`, false},
+		{"extra statement", `print 1;`, `print 1; print 1;`, false},
+		{"statement type", `print a;`, `a;`, false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			a, b := parse(t, tc.a), parse(t, tc.b)
+			assert.Equal(t, tc.equal, parser.EqualStmts(a, b))
+			assert.Equal(t, tc.equal, parser.EqualStmts(b, a))
+			if len(a) == len(b) {
+				assert.Equal(t, tc.equal, parser.Equal(a[0], b[0]) && parser.EqualStmts(a[1:], b[1:]))
+			}
+		})
+	}
+}
+
+func TestEqualExpr(t *testing.T) {
+	t.Parallel()
+
+	parseExpr := func(input string) parser.Expr {
+		return parse(t, input)[0].(*parser.StmtExpression).Expression
+	}
+
+	assert.True(t, parser.EqualExpr(parseExpr(`a.b(1, [2, {"k": 3}]);`), parseExpr(`a . b ( 1 , [ 2 , { "k" : 3 } ] ) ;`)))
+	assert.False(t, parser.EqualExpr(parseExpr(`a.b(1);`), parseExpr(`a.c(1);`)))
+	assert.True(t, parser.EqualExpr(nil, nil))
+	assert.False(t, parser.EqualExpr(parseExpr(`a;`), nil))
+}
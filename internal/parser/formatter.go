@@ -0,0 +1,392 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/leonardinius/golox/internal/token"
+)
+
+const formatIndent = "  "
+
+type FormatOption func(*formatter)
+
+// WithComments re-emits the comments the parser associated with the statements, see Parser.Comments.
+func WithComments(comments *Comments) FormatOption {
+	return func(f *formatter) {
+		f.comments = comments
+	}
+}
+
+// Format renders the statements back to the canonical Lox source, one statement per line.
+// The formatted source parses to the same syntax tree, formatting it again yields the same output.
+func Format(stmts []Stmt, options ...FormatOption) string {
+	f := &formatter{}
+	for _, opt := range options {
+		opt(f)
+	}
+	var sb strings.Builder
+	f.lines(&sb, stmts)
+	f.commentLines(&sb, f.comments.Trailing())
+	return sb.String()
+}
+
+// formatter renders the nodes, the depth is the indentation level of the current statement.
+type formatter struct {
+	depth    int
+	comments *Comments
+}
+
+// lines renders the statements with their comments, one statement per line at the current depth.
+func (f *formatter) lines(sb *strings.Builder, stmts []Stmt) {
+	for _, stmt := range stmts {
+		key := stmt
+		if method, ok := stmt.(*formatMethod); ok {
+			key = method.method
+		}
+		f.commentLines(sb, f.comments.Leading(key))
+		sb.WriteString(strings.Repeat(formatIndent, f.depth))
+		sb.WriteString(f.stmt(stmt))
+		if comment, ok := f.comments.Inline(key); ok {
+			sb.WriteString(" " + comment.Lexeme)
+		}
+		sb.WriteByte('\n')
+	}
+}
+
+func (f *formatter) commentLines(sb *strings.Builder, comments []token.Token) {
+	for _, comment := range comments {
+		sb.WriteString(strings.Repeat(formatIndent, f.depth))
+		sb.WriteString(comment.Lexeme)
+		sb.WriteByte('\n')
+	}
+}
+
+func (f *formatter) stmt(stmt Stmt) string {
+	value, _ := stmt.Accept(f)
+	return value.(string)
+}
+
+func (f *formatter) expr(expr Expr) string {
+	value, _ := expr.Accept(f)
+	return value.(string)
+}
+
+func (f *formatter) exprs(exprs []Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = f.expr(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// block renders the block statements in braces, see body.
+func (f *formatter) block(stmts *[]Stmt) string {
+	return f.body(stmts, *stmts)
+}
+
+// body renders the statements and the closing comments of the key in braces, indented one level deeper than the current statement.
+func (f *formatter) body(key any, stmts []Stmt) string {
+	closing := f.comments.Closing(key)
+	if len(stmts) == 0 && len(closing) == 0 {
+		return "{}"
+	}
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	f.depth++
+	f.lines(&sb, stmts)
+	f.commentLines(&sb, closing)
+	f.depth--
+	sb.WriteString(strings.Repeat(formatIndent, f.depth))
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func (f *formatter) function(name string, fn *ExprFunction) string {
+	return name + "(" + strings.Join(paramNames(fn), ", ") + ") " + f.block(&fn.Body)
+}
+
+// paramNames returns the function parameters as declared, the rest parameter with the `...` prefix.
+func paramNames(fn *ExprFunction) []string {
+	names := make([]string, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		names[i] = param.Lexeme
+	}
+	if fn.IsVariadic {
+		names[len(names)-1] = "..." + names[len(names)-1]
+	}
+	return names
+}
+
+// VisitExprArray implements ExprVisitor.
+func (f *formatter) VisitExprArray(exprArray *ExprArray) (any, error) {
+	return "[" + f.exprs(exprArray.Elements) + "]", nil
+}
+
+// VisitExprAssign implements ExprVisitor.
+func (f *formatter) VisitExprAssign(exprAssign *ExprAssign) (any, error) {
+	return exprAssign.Name.Lexeme + " = " + f.expr(exprAssign.Value), nil
+}
+
+// VisitExprBinary implements ExprVisitor.
+func (f *formatter) VisitExprBinary(exprBinary *ExprBinary) (any, error) {
+	return f.expr(exprBinary.Left) + " " + exprBinary.Operator.Lexeme + " " + f.expr(exprBinary.Right), nil
+}
+
+// VisitExprCall implements ExprVisitor.
+func (f *formatter) VisitExprCall(exprCall *ExprCall) (any, error) {
+	return f.expr(exprCall.Callee) + "(" + f.exprs(exprCall.Arguments) + ")", nil
+}
+
+// VisitExprFunction implements ExprVisitor.
+func (f *formatter) VisitExprFunction(exprFunction *ExprFunction) (any, error) {
+	return f.function("fun ", exprFunction), nil
+}
+
+// VisitExprGet implements ExprVisitor.
+func (f *formatter) VisitExprGet(exprGet *ExprGet) (any, error) {
+	return f.expr(exprGet.Instance) + "." + exprGet.Name.Lexeme, nil
+}
+
+// VisitExprGrouping implements ExprVisitor.
+func (f *formatter) VisitExprGrouping(exprGrouping *ExprGrouping) (any, error) {
+	return "(" + f.expr(exprGrouping.Expression) + ")", nil
+}
+
+// VisitExprLiteral implements ExprVisitor.
+func (f *formatter) VisitExprLiteral(exprLiteral *ExprLiteral) (any, error) {
+	switch value := exprLiteral.Value.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		// the scanner doesn't support the exponent notation
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case string:
+		return `"` + value + `"`, nil
+	default:
+		panic("unexpected literal")
+	}
+}
+
+// VisitExprLogical implements ExprVisitor.
+func (f *formatter) VisitExprLogical(exprLogical *ExprLogical) (any, error) {
+	return f.expr(exprLogical.Left) + " " + exprLogical.Operator.Lexeme + " " + f.expr(exprLogical.Right), nil
+}
+
+// VisitExprMap implements ExprVisitor.
+func (f *formatter) VisitExprMap(exprMap *ExprMap) (any, error) {
+	entries := make([]string, len(exprMap.Keys))
+	for index, key := range exprMap.Keys {
+		entries[index] = f.expr(key) + ": " + f.expr(exprMap.Values[index])
+	}
+	return "{" + strings.Join(entries, ", ") + "}", nil
+}
+
+// VisitExprSet implements ExprVisitor.
+func (f *formatter) VisitExprSet(exprSet *ExprSet) (any, error) {
+	return f.expr(exprSet.Instance) + "." + exprSet.Name.Lexeme + " = " + f.expr(exprSet.Value), nil
+}
+
+// VisitExprSuper implements ExprVisitor.
+func (f *formatter) VisitExprSuper(exprSuper *ExprSuper) (any, error) {
+	return "super." + exprSuper.Method.Lexeme, nil
+}
+
+// VisitExprThis implements ExprVisitor.
+func (f *formatter) VisitExprThis(exprThis *ExprThis) (any, error) {
+	return "this", nil
+}
+
+// VisitExprTypeCheck implements ExprVisitor.
+func (f *formatter) VisitExprTypeCheck(exprTypeCheck *ExprTypeCheck) (any, error) {
+	return f.expr(exprTypeCheck.Instance) + " is " + f.expr(exprTypeCheck.Class), nil
+}
+
+// VisitExprPostfix implements ExprVisitor.
+func (f *formatter) VisitExprPostfix(exprPostfix *ExprPostfix) (any, error) {
+	return f.expr(exprPostfix.Target) + exprPostfix.Operator.Lexeme, nil
+}
+
+// VisitExprUnary implements ExprVisitor.
+func (f *formatter) VisitExprUnary(exprUnary *ExprUnary) (any, error) {
+	right := f.expr(exprUnary.Right)
+	if exprUnary.Operator.Type == token.MINUS && strings.HasPrefix(right, "-") {
+		// keeps `- -a` apart, not to be read as a single token
+		return exprUnary.Operator.Lexeme + " " + right, nil
+	}
+	return exprUnary.Operator.Lexeme + right, nil
+}
+
+// VisitExprVariable implements ExprVisitor.
+func (f *formatter) VisitExprVariable(exprVariable *ExprVariable) (any, error) {
+	return exprVariable.Name.Lexeme, nil
+}
+
+// VisitStmtBlock implements StmtVisitor.
+func (f *formatter) VisitStmtBlock(stmtBlock *StmtBlock) (any, error) {
+	return f.block(&stmtBlock.Statements), nil
+}
+
+// VisitStmtClass implements StmtVisitor.
+func (f *formatter) VisitStmtClass(stmtClass *StmtClass) (any, error) {
+	header := "class " + stmtClass.Name.Lexeme
+	if stmtClass.SuperClass != nil {
+		header += " < " + stmtClass.SuperClass.Name.Lexeme
+	}
+
+	members := make([]Stmt, 0, len(stmtClass.Fields)+len(stmtClass.Methods)+len(stmtClass.ClassMethods))
+	for _, field := range stmtClass.Fields {
+		members = append(members, field)
+	}
+	for _, method := range stmtClass.Methods {
+		members = append(members, &formatMethod{method: method})
+	}
+	for _, method := range stmtClass.ClassMethods {
+		members = append(members, &formatMethod{method: method, static: true})
+	}
+	return header + " " + f.body(stmtClass, members), nil
+}
+
+// VisitStmtExpression implements StmtVisitor.
+func (f *formatter) VisitStmtExpression(stmtExpression *StmtExpression) (any, error) {
+	return f.expr(stmtExpression.Expression) + ";", nil
+}
+
+// VisitStmtFunction implements StmtVisitor.
+func (f *formatter) VisitStmtFunction(stmtFunction *StmtFunction) (any, error) {
+	return f.function("fun "+stmtFunction.Name.Lexeme, stmtFunction.Fn), nil
+}
+
+// VisitStmtIf implements StmtVisitor.
+func (f *formatter) VisitStmtIf(stmtIf *StmtIf) (any, error) {
+	s := "if (" + f.expr(stmtIf.Condition) + ") " + f.stmt(stmtIf.ThenBranch)
+	if stmtIf.ElseBranch != nil {
+		s += " else " + f.stmt(stmtIf.ElseBranch)
+	}
+	return s, nil
+}
+
+// VisitStmtPrint implements StmtVisitor.
+func (f *formatter) VisitStmtPrint(stmtPrint *StmtPrint) (any, error) {
+	return "print " + f.exprs(stmtPrint.Expressions) + ";", nil
+}
+
+// VisitStmtReturn implements StmtVisitor.
+func (f *formatter) VisitStmtReturn(stmtReturn *StmtReturn) (any, error) {
+	if stmtReturn.Value == nil {
+		return "return;", nil
+	}
+	return "return " + f.expr(stmtReturn.Value) + ";", nil
+}
+
+// VisitStmtVar implements StmtVisitor.
+func (f *formatter) VisitStmtVar(stmtVar *StmtVar) (any, error) {
+	keyword := "var "
+	if stmtVar.IsConst {
+		keyword = "const "
+	}
+	if stmtVar.Initializer == nil {
+		return keyword + stmtVar.Name.Lexeme + ";", nil
+	}
+	return keyword + stmtVar.Name.Lexeme + " = " + f.expr(stmtVar.Initializer) + ";", nil
+}
+
+// VisitStmtWhile implements StmtVisitor.
+func (f *formatter) VisitStmtWhile(stmtWhile *StmtWhile) (any, error) {
+	return loopLabel(stmtWhile.Label) + "while (" + f.expr(stmtWhile.Condition) + ") " + f.stmt(stmtWhile.Body), nil
+}
+
+// VisitStmtFor implements StmtVisitor.
+func (f *formatter) VisitStmtFor(stmtFor *StmtFor) (any, error) {
+	s := loopLabel(stmtFor.Label) + "for ("
+	if stmtFor.Initializer == nil {
+		s += ";"
+	} else {
+		s += f.stmt(stmtFor.Initializer)
+	}
+	s += " " + f.expr(stmtFor.Condition) + ";"
+	if stmtFor.Increment != nil {
+		s += " " + f.expr(stmtFor.Increment)
+	}
+	return s + ") " + f.stmt(stmtFor.Body), nil
+}
+
+// VisitStmtForEach implements StmtVisitor.
+func (f *formatter) VisitStmtForEach(stmtForEach *StmtForEach) (any, error) {
+	return loopLabel(stmtForEach.Label) + "for (var " + stmtForEach.Name.Lexeme + " in " + f.expr(stmtForEach.Iterable) + ") " + f.stmt(stmtForEach.Body), nil
+}
+
+// VisitStmtTry implements StmtVisitor.
+func (f *formatter) VisitStmtTry(stmtTry *StmtTry) (any, error) {
+	s := "try " + f.block(&stmtTry.Body)
+	if stmtTry.CatchName != nil {
+		s += " catch (" + stmtTry.CatchName.Lexeme + ") " + f.block(&stmtTry.CatchBody)
+	}
+	if stmtTry.FinallyBody != nil {
+		s += " finally " + f.block(&stmtTry.FinallyBody)
+	}
+	return s, nil
+}
+
+// VisitStmtThrow implements StmtVisitor.
+func (f *formatter) VisitStmtThrow(stmtThrow *StmtThrow) (any, error) {
+	return "throw " + f.expr(stmtThrow.Value) + ";", nil
+}
+
+// VisitStmtBreak implements StmtVisitor.
+func (f *formatter) VisitStmtBreak(stmtBreak *StmtBreak) (any, error) {
+	return "break" + jumpTarget(stmtBreak.Label) + ";", nil
+}
+
+// VisitStmtContinue implements StmtVisitor.
+func (f *formatter) VisitStmtContinue(stmtContinue *StmtContinue) (any, error) {
+	return "continue" + jumpTarget(stmtContinue.Label) + ";", nil
+}
+
+// loopLabel renders the loop label prefix, empty for the unlabeled loop.
+func loopLabel(tok *token.Token) string {
+	if tok == nil {
+		return ""
+	}
+	return tok.Lexeme + ": "
+}
+
+// jumpTarget renders the `break` or `continue` target label, empty for the innermost loop.
+func jumpTarget(tok *token.Token) string {
+	if tok == nil {
+		return ""
+	}
+	return " " + tok.Lexeme
+}
+
+// VisitStmtEmpty implements StmtVisitor.
+func (f *formatter) VisitStmtEmpty(stmtEmpty *StmtEmpty) (any, error) {
+	return ";", nil
+}
+
+// formatMethod is the class body member, rendered without the `fun` keyword.
+type formatMethod struct {
+	method *StmtFunction
+	static bool
+}
+
+// Accept implements Stmt.
+func (m *formatMethod) Accept(v StmtVisitor) (any, error) {
+	f := v.(*formatter)
+	name := m.method.Name.Lexeme
+	if m.static {
+		name = "class " + name
+	}
+	if m.method.IsGetter {
+		return name + " " + f.block(&m.method.Fn.Body), nil
+	}
+	return f.function(name, m.method.Fn), nil
+}
+
+var (
+	_ ExprVisitor = (*formatter)(nil)
+	_ StmtVisitor = (*formatter)(nil)
+	_ Stmt        = (*formatMethod)(nil)
+)
@@ -0,0 +1,170 @@
+package parser_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"var", `var   a=1+2 ;var b;`, "var a = 1 + 2;\nvar b;\n"},
+		{"print many", `print a,b+1 ,"c";`, "print a, b + 1, \"c\";\n"},
+		{
+			"if else",
+			`if(a>1){print a;}else if (b) print b; else {print -(-c);}`,
+			"if (a > 1) {\n  print a;\n} else if (b) print b; else {\n  print -(-c);\n}\n",
+		},
+		{
+			"while",
+			`while (i < 10) { i = i + 1; if (i == 5) { break; } continue; }`,
+			"while (i < 10) {\n  i = i + 1;\n  if (i == 5) {\n    break;\n  }\n  continue;\n}\n",
+		},
+		{
+			"class",
+			`class A < B { var x = 1; init(x) { this.x = x; } area { return this.x * 2; } class make() { return A(1); } m() { return super.m() and this is A; } }`,
+			"class A < B {\n" +
+				"  var x = 1;\n" +
+				"  init(x) {\n    this.x = x;\n  }\n" +
+				"  area {\n    return this.x * 2;\n  }\n" +
+				"  m() {\n    return super.m() and this is A;\n  }\n" +
+				"  class make() {\n    return A(1);\n  }\n" +
+				"}\n",
+		},
+		{
+			"functions",
+			`fun f(a, b) { return fun (c) { return [a, b, c]; }; } fun g() {} f(1, 2)("s");`,
+			"fun f(a, b) {\n  return fun (c) {\n    return [a, b, c];\n  };\n}\nfun g() {}\nf(1, 2)(\"s\");\n",
+		},
+		{
+			"loops",
+			`for (;;) break; for (var i = 0; i < 2; i = i + 1) print i; for (i = 0; i < 2;) {} for (var x in [1.50, nil, true]) print x;`,
+			"for (; true;) break;\nfor (var i = 0; i < 2; i = i + 1) print i;\nfor (i = 0; i < 2;) {}\nfor (var x in [1.5, nil, true]) print x;\n",
+		},
+		{
+			"labeled loops",
+			`outer:for(;;){inner:while(a)break outer;rows:for(var x in xs)continue outer;}`,
+			"outer: for (; true;) {\n  inner: while (a) break outer;\n  rows: for (var x in xs) continue outer;\n}\n",
+		},
+		{
+			"try",
+			`try { throw "e"; } catch (e) { print e; } finally { print 1; }`,
+			"try {\n  throw \"e\";\n} catch (e) {\n  print e;\n} finally {\n  print 1;\n}\n",
+		},
+		{"const", `const  a=1;{const b = a;print b;}`, "const a = 1;\n{\n  const b = a;\n  print b;\n}\n"},
+		{"empty statements", `;while(a);{;}`, ";\nwhile (a) ;\n{\n  ;\n}\n"},
+		{"postfix", `for(var i=0;i<2;i++)a.n--; - -b;`, "for (var i = 0; i < 2; i++) a.n--;\n- -b;\n"},
+		{"variadic", `fun f(a,...rest){} fun (...xs) {};`, "fun f(a, ...rest) {}\nfun (...xs) {};\n"},
+		{"map", `var m={ "a":1,2:{} ,};`, "var m = {\"a\": 1, 2: {}};\n"},
+		{"unary", `print !!a; print - -1; print -(-1);`, "print !!a;\nprint - -1;\nprint -(-1);\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stmts := parse(t, tc.input)
+			formatted := parser.Format(stmts)
+			assert.Equal(t, tc.expected, formatted)
+
+			// round trip: the same syntax tree and the stable formatting
+			reparsed := parse(t, formatted)
+			assert.Equal(t, parser.NewAstPrinter().Print(stmts), parser.NewAstPrinter().Print(reparsed))
+			assert.Equal(t, formatted, parser.Format(reparsed))
+		})
+	}
+}
+
+func TestFormatWithComments(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"function doc comment",
+			"// add returns the sum.\n// Both are numbers.\nfun add(a,b){return a+b;}",
+			"// add returns the sum.\n// Both are numbers.\nfun add(a, b) {\n  return a + b;\n}\n",
+		},
+		{
+			"block comments",
+			"fun f() {\n/* first */ var a = 1;\n  // last\n}\nprint f(); // call\n// end",
+			"fun f() {\n  /* first */\n  var a = 1;\n  // last\n}\nprint f(); // call\n// end\n",
+		},
+		{
+			"class members",
+			"class A {\n// the x\nvar x = 1; // one\n  // the m\n  m() {}\n}",
+			"class A {\n  // the x\n  var x = 1; // one\n  // the m\n  m() {}\n}\n",
+		},
+		{"empty block", "{ // todo\n}\nprint 1;", "{\n  // todo\n}\nprint 1;\n"},
+		{"empty function", "fun f() {\n  // todo\n}\nprint 1;", "fun f() {\n  // todo\n}\nprint 1;\n"},
+		{
+			"class body end",
+			"class A {\n  m() {}\n  // end of A\n}\nclass B { /* empty */ }\nprint 1;",
+			"class A {\n  m() {}\n  // end of A\n}\nclass B {\n  /* empty */\n}\nprint 1;\n",
+		},
+		{
+			"try blocks",
+			"try { // try\n} catch (e) { // catch\n} finally {\n// finally\n}",
+			"try {\n  // try\n} catch (e) {\n  // catch\n} finally {\n  // finally\n}\n",
+		},
+		{
+			"if branches",
+			"if (a) // then\n  print 1;\nelse\n  print 2;\nprint 3;",
+			"// then\nif (a) print 1; else print 2;\nprint 3;\n",
+		},
+		{
+			"if block branches",
+			"if (a) {\n  print 1;\n  // then end\n} else { // else\n}\nprint 3;",
+			"if (a) {\n  print 1;\n  // then end\n} else {\n  // else\n}\nprint 3;\n",
+		},
+		{
+			"call arguments",
+			"{\n  f(1, // one\n    2 /* two */); // call\n  print 3;\n}",
+			"{\n  // one\n  /* two */\n  f(1, 2); // call\n  print 3;\n}\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			formatted := formatWithComments(t, tc.input)
+			assert.Equal(t, tc.expected, formatted)
+			assert.Equal(t, formatted, formatWithComments(t, formatted))
+		})
+	}
+}
+
+func formatWithComments(t *testing.T, input string) string {
+	t.Helper()
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	tokens, err := scanner.NewScanner(input, reporter, scanner.WithComments()).Scan()
+	require.NoError(t, err)
+	p := parser.NewParser(tokens, reporter)
+	stmts, err := p.Parse()
+	require.NoError(t, err)
+	return parser.Format(stmts, parser.WithComments(p.Comments()))
+}
+
+func parse(t *testing.T, input string) []parser.Stmt {
+	t.Helper()
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	tokens, err := scanner.NewScanner(input, reporter).Scan()
+	require.NoError(t, err)
+	stmts, err := parser.NewParser(tokens, reporter).Parse()
+	require.NoError(t, err)
+	return stmts
+}
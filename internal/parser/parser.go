@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
 	"github.com/leonardinius/golox/internal/token"
@@ -12,10 +13,16 @@ var (
 	nilStmt       Stmt   = nil
 	nilStatements []Stmt = nil
 	maxArguments         = 255
+	// maxExpressionDepth is the default expressions nesting limit, see WithMaxExpressionDepth.
+	maxExpressionDepth = 1000
 )
 
 type Parser interface {
 	Parse() ([]Stmt, error)
+	// ParseExpression parses the single expression, the trailing semicolon is optional.
+	ParseExpression() (Expr, error)
+	// Comments returns the COMMENT tokens associated with the parsed statements.
+	Comments() *Comments
 }
 
 type parser struct {
@@ -23,34 +30,73 @@ type parser struct {
 	current   int
 	reporter  loxerrors.ErrReporter
 	loopDepth int
+	// labels are the labels of the enclosing loops, the innermost last.
+	labels    []string
 	funcDepth int
-	panic     error
-	err       error
+	// exprDepth is the nesting of the expressions and blocks being parsed, limited by maxExprDepth.
+	exprDepth    int
+	maxExprDepth int
+	// panic is the fatal error of the current declaration, reset on synchronize.
+	panic error
+	// errs are all the errors reported while parsing.
+	errs []error
+	// pending are the comments keyed by the index of the token they precede, nextComment is the first not taken.
+	pending     map[int][]token.Token
+	nextComment int
+	comments    *Comments
 }
 
-func NewParser(tokens []token.Token, reporter loxerrors.ErrReporter) Parser {
+type ParserOption func(*parser)
+
+// WithMaxExpressionDepth limits the expressions nesting (e.g. the parentheses), the deeper expression fails with
+// loxerrors.ErrParseExpressionTooDeep instead of exhausting the stack. The default limit is 1000.
+func WithMaxExpressionDepth(depth int) ParserOption {
+	return func(p *parser) {
+		p.maxExprDepth = depth
+	}
+}
+
+// NewParser returns a new Parser for the scanned tokens, panics if the tokens don't end with EOF.
+func NewParser(tokens []token.Token, reporter loxerrors.ErrReporter, options ...ParserOption) Parser {
+	p, err := NewParserChecked(tokens, reporter, options...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewParserChecked is NewParser returning an error instead of panicking, for the tokens built programmatically.
+func NewParserChecked(tokens []token.Token, reporter loxerrors.ErrReporter, options ...ParserOption) (Parser, error) {
 	if len(tokens) == 0 {
-		panic("tokens cannot be empty")
+		return nil, loxerrors.ErrParseTokensEmpty
 	}
 	if tokens[len(tokens)-1].Type != token.EOF {
-		panic("tokens must end with EOF")
+		return nil, loxerrors.ErrParseTokensMissingEOF
 	}
 
-	return &parser{
-		tokens:   tokens,
-		current:  0,
-		reporter: reporter,
+	tokens, pending := splitComments(tokens)
+	p := &parser{
+		tokens:       tokens,
+		current:      0,
+		reporter:     reporter,
+		maxExprDepth: maxExpressionDepth,
+		pending:      pending,
+		comments:     newComments(),
+	}
+	for _, opt := range options {
+		opt(p)
 	}
+	return p, nil
 }
 
 // GoString implements fmt.GoStringer.
 func (p *parser) GoString() string {
-	return fmt.Sprintf("parser{tokens: %#v, current: %d, err: %#v}", p.tokens, p.current, p.panic)
+	return fmt.Sprintf("parser{tokens: %#v, current: %d, err: %#v, errs: %#v}", p.tokens, p.current, p.panic, p.errs)
 }
 
 // String implements fmt.Stringer.
 func (p *parser) String() string {
-	return fmt.Sprintf("parser{tokens: %d, err: %v}", len(p.tokens), p.panic)
+	return fmt.Sprintf("parser{tokens: %d, err: %v, errs: %d}", len(p.tokens), p.panic, len(p.errs))
 }
 
 // Parse implements Parser.
@@ -63,21 +109,48 @@ func (p *parser) Parse() (statements []Stmt, err error) {
 		}
 		statements = append(statements, stmt)
 	}
+	p.comments.trailing = append(p.comments.trailing, p.takeComments(p.current)...)
 
-	if p.panic == nil && p.err == nil {
+	if p.panic == nil && len(p.errs) == 0 {
 		return statements, nil
 	}
 
 	return nilStatements, loxerrors.ErrParseError
 }
 
+// Comments implements Parser.
+func (p *parser) Comments() *Comments {
+	return p.comments
+}
+
+func (p *parser) ParseExpression() (Expr, error) {
+	expr := p.expression()
+	if p.panic == nil {
+		p.match(token.SEMICOLON)
+		if !p.isAtEnd() {
+			p.reportFatalErrorExpr(loxerrors.ErrParseExpectedEndOfExpression)
+		}
+	}
+
+	if p.panic == nil && len(p.errs) == 0 {
+		return expr, nil
+	}
+
+	return nilExpr, loxerrors.ErrParseError
+}
+
 func (p *parser) declaration() Stmt {
+	comments := p.takeComments(p.current)
 	stmt := p.tryDeclaration()
 	if p.panic != nil && !p.isAtEnd() {
 		// attempt to recover
 		p.synchronize()
 		p.panic = nil
 	}
+	// the comments within the statement, e.g. between the call arguments, are kept before it
+	comments = append(comments, p.takeComments(p.current-1)...)
+	addComments(p.comments.leading, stmt, comments)
+	p.inlineComment(stmt)
 	return stmt
 }
 
@@ -95,6 +168,10 @@ func (p *parser) tryDeclaration() Stmt {
 		return p.varDeclaration()
 	}
 
+	if p.match(token.CONST) {
+		return p.constDeclaration()
+	}
+
 	return p.statement()
 }
 
@@ -116,21 +193,39 @@ func (p *parser) classDeclaration() Stmt {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectLeftCurlyBeforeClassBody)
 	}
 
+	var fields []*StmtVar
 	var methods []*StmtFunction
 	var classMethods []*StmtFunction
 	for !p.check(token.RIGHT_BRACE) && !p.isDone() {
-		if p.match(token.CLASS) {
-			classMethods = append(classMethods, p.funDeclaration("method"))
+		comments := p.takeComments(p.current)
+		var member Stmt
+		if p.match(token.VAR) {
+			if field, ok := p.varDeclaration().(*StmtVar); ok {
+				fields = append(fields, field)
+				member = field
+			}
+		} else if p.match(token.CLASS) {
+			method := p.funDeclaration("method")
+			classMethods = append(classMethods, method)
+			member = method
 		} else {
-			methods = append(methods, p.funDeclaration("method"))
+			method := p.funDeclaration("method")
+			methods = append(methods, method)
+			member = method
 		}
+		comments = append(comments, p.takeComments(p.current-1)...)
+		addComments(p.comments.leading, member, comments)
+		p.inlineComment(member)
 	}
+	closing := p.takeComments(p.current)
 
 	if !p.match(token.RIGHT_BRACE) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectRightCurlyAfterClassBody)
 	}
 
-	return &StmtClass{Name: name, SuperClass: superClass, Methods: methods, ClassMethods: classMethods}
+	class := &StmtClass{Name: name, SuperClass: superClass, Fields: fields, Methods: methods, ClassMethods: classMethods}
+	addComments(p.comments.closing, any(class), closing)
+	return class
 }
 
 func (p *parser) funDeclaration(kind string) *StmtFunction {
@@ -140,25 +235,38 @@ func (p *parser) funDeclaration(kind string) *StmtFunction {
 		return nil
 	}
 	name := p.previous()
-	if fn, ok := p.functionBody(kind).(*ExprFunction); ok {
+
+	// getter method, declared without parameters list
+	if kind == "method" && p.check(token.LEFT_BRACE) {
+		if fn, ok := p.functionBlock(kind, nil, false).(*ExprFunction); ok {
+			return &StmtFunction{Name: name, Fn: fn, IsGetter: true}
+		}
+		return nil
+	}
+
+	if fn, ok := p.functionBody(kind, false).(*ExprFunction); ok {
 		return &StmtFunction{Name: name, Fn: fn}
 	}
 
 	return nil
 }
 
-func (p *parser) functionBody(kind string) Expr {
+// functionBody parses the parameters and the body, the arrow allows the `=> expression` body, e.g. for the anonymous functions.
+func (p *parser) functionBody(kind string, arrow bool) Expr {
 	if !p.match(token.LEFT_PAREN) {
 		return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedLeftParenError(kind))
 	}
 
 	var params []*token.Token
+	variadic := false
 	if !p.check(token.RIGHT_PAREN) {
 		for {
 			if len(params) >= maxArguments {
 				p.reportErrorExpr(loxerrors.ErrParseTooManyParameters)
 			}
 
+			// the rest parameter `...name` collects the surplus arguments
+			variadic = p.match(token.ELLIPSIS)
 			if !p.match(token.IDENTIFIER) {
 				return p.reportFatalErrorExpr(loxerrors.ErrParseUnexpectedParameterName)
 			}
@@ -167,22 +275,56 @@ func (p *parser) functionBody(kind string) Expr {
 			if !p.match(token.COMMA) {
 				break
 			}
+			if variadic {
+				return p.reportFatalErrorExprToken(params[len(params)-1], loxerrors.ErrParseRestParameterMustBeLast)
+			}
 		}
 	}
 
-	// function body
 	if !p.match(token.RIGHT_PAREN) {
 		return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedRightParentFunToken)
 	}
+
+	if arrow && p.match(token.ARROW) {
+		return p.arrowBody(params, variadic)
+	}
+
+	return p.functionBlock(kind, params, variadic)
+}
+
+// arrowBody parses the `=> expression` body, the sugar for `{ return expression; }`.
+func (p *parser) arrowBody(params []*token.Token, variadic bool) Expr {
+	keyword := p.previous()
+
+	p.funcDepth++
+	defer func() { p.funcDepth-- }()
+	defer p.setLabels(p.setLabels(nil))
+	value := p.expression()
+
+	body := []Stmt{&StmtReturn{Keyword: keyword, Value: value}}
+	return &ExprFunction{Parameters: params, Body: body, IsVariadic: variadic}
+}
+
+func (p *parser) functionBlock(kind string, params []*token.Token, variadic bool) Expr {
 	if !p.match(token.LEFT_BRACE) {
 		return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedLeftBraceFunToken(kind))
 	}
 
 	p.funcDepth++
 	defer func() { p.funcDepth-- }()
-	body := p.blockStatement()
+	defer p.setLabels(p.setLabels(nil))
+	fn := &ExprFunction{Parameters: params, IsVariadic: variadic}
+	p.blockStatement(&fn.Body)
 
-	return &ExprFunction{Parameters: params, Body: body}
+	return fn
+}
+
+// inferFunctionName names the anonymous function after the variable or the field it is assigned to, for the display and the stack traces.
+func inferFunctionName(name *token.Token, value Expr) Expr {
+	if fn, ok := value.(*ExprFunction); ok && fn.InferredName == nil {
+		fn.InferredName = name
+	}
+	return value
 }
 
 func (p *parser) varDeclaration() Stmt {
@@ -193,7 +335,7 @@ func (p *parser) varDeclaration() Stmt {
 
 	initializer := nilExpr
 	if p.match(token.EQUAL) {
-		initializer = p.expression()
+		initializer = inferFunctionName(name, p.expression())
 	}
 
 	if !p.match(token.SEMICOLON) {
@@ -203,7 +345,30 @@ func (p *parser) varDeclaration() Stmt {
 	return &StmtVar{Name: name, Initializer: initializer}
 }
 
+// constDeclaration parses `const name = value;`, the variable which can't be reassigned.
+func (p *parser) constDeclaration() Stmt {
+	stmt, ok := p.varDeclaration().(*StmtVar)
+	if !ok {
+		return nilStmt
+	}
+	if stmt.Initializer == nil {
+		p.reportErrorExprToken(stmt.Name, loxerrors.ErrParseExpectedConstInitializer)
+	}
+
+	stmt.IsConst = true
+	return stmt
+}
+
 func (p *parser) statement() Stmt {
+	if p.match(token.SEMICOLON) {
+		// the lone semicolon is the empty statement, e.g. `while (poll());`
+		return &StmtEmpty{Semicolon: p.previous()}
+	}
+
+	if p.check(token.IDENTIFIER) && p.checkNext(token.COLON) {
+		return p.labeledStatement()
+	}
+
 	if p.match(token.FOR) {
 		return p.forStatement()
 	}
@@ -229,17 +394,77 @@ func (p *parser) statement() Stmt {
 	}
 
 	if p.match(token.LEFT_BRACE) {
-		block := p.blockStatement()
-		return &StmtBlock{Statements: block}
+		block := &StmtBlock{}
+		p.blockStatement(&block.Statements)
+		return block
 	}
 
 	if p.match(token.CONTINUE) {
 		return p.continueStatement()
 	}
 
+	if p.match(token.TRY) {
+		return p.tryStatement()
+	}
+
+	if p.match(token.THROW) {
+		return p.throwStatement()
+	}
+
 	return p.expressionStatement()
 }
 
+func (p *parser) tryStatement() Stmt {
+	if !p.match(token.LEFT_BRACE) {
+		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftBraceAfterTry)
+	}
+	stmt := &StmtTry{}
+	p.blockStatement(&stmt.Body)
+
+	if p.match(token.CATCH) {
+		if !p.match(token.LEFT_PAREN) {
+			return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftParenAfterCatch)
+		}
+		if !p.match(token.IDENTIFIER) {
+			return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedCatchVariableName)
+		}
+		stmt.CatchName = p.previous()
+		if !p.match(token.RIGHT_PAREN) {
+			return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedRightParenAfterCatch)
+		}
+		if !p.match(token.LEFT_BRACE) {
+			return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftBraceAfterCatch)
+		}
+		p.blockStatement(&stmt.CatchBody)
+	}
+
+	if p.match(token.FINALLY) {
+		if !p.match(token.LEFT_BRACE) {
+			return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftBraceAfterFinally)
+		}
+		// non-nil even if empty, nil means there is no finally clause
+		stmt.FinallyBody = []Stmt{}
+		p.blockStatement(&stmt.FinallyBody)
+	}
+
+	if stmt.CatchName == nil && stmt.FinallyBody == nil {
+		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedCatchOrFinally)
+	}
+
+	return stmt
+}
+
+func (p *parser) throwStatement() Stmt {
+	keyword := p.previous()
+	value := p.expression()
+
+	if !p.match(token.SEMICOLON) {
+		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedSemicolonTokenAfterThrow)
+	}
+
+	return &StmtThrow{Keyword: keyword, Value: value}
+}
+
 func (p *parser) ifStatement() Stmt {
 	if !p.match(token.LEFT_PAREN) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftParentIfToken)
@@ -261,13 +486,17 @@ func (p *parser) ifStatement() Stmt {
 }
 
 func (p *parser) printStatement() Stmt {
-	expr := p.expression()
+	keyword := p.previous()
+	exprs := []Expr{p.expression()}
+	for p.match(token.COMMA) {
+		exprs = append(exprs, p.expression())
+	}
 
 	if !p.match(token.SEMICOLON) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedSemicolonTokenAfterPrintValue)
 	}
 
-	return &StmtPrint{Expression: expr}
+	return &StmtPrint{Keyword: keyword, Expressions: exprs}
 }
 
 func (p *parser) returnStatement() Stmt {
@@ -289,6 +518,61 @@ func (p *parser) returnStatement() Stmt {
 	return &StmtReturn{Keyword: tok, Value: value}
 }
 
+// labeledStatement parses the `label: while (...)` or `label: for (...)` loop.
+func (p *parser) labeledStatement() Stmt {
+	p.advance()
+	label := p.previous()
+	p.advance() // :
+	if slices.Contains(p.labels, label.Lexeme) {
+		// the loop is still parsed, the enclosing loops jumps stay valid
+		p.reportErrorExprToken(label, loxerrors.ErrParseDuplicateLabelError(label.Lexeme))
+	}
+
+	p.labels = append(p.labels, label.Lexeme)
+	defer func() { p.labels = p.labels[:len(p.labels)-1] }()
+
+	var loop Stmt
+	switch {
+	case p.match(token.WHILE):
+		loop = p.whileStatement()
+	case p.match(token.FOR):
+		loop = p.forStatement()
+	default:
+		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLoopAfterLabel)
+	}
+
+	switch loop := loop.(type) {
+	case *StmtWhile:
+		loop.Label = label
+	case *StmtFor:
+		loop.Label = label
+	case *StmtForEach:
+		loop.Label = label
+	}
+	return loop
+}
+
+// setLabels replaces the enclosing loop labels, returns the previous ones.
+// The function body doesn't see the labels of the loops it is declared in.
+func (p *parser) setLabels(labels []string) []string {
+	old := p.labels
+	p.labels = labels
+	return old
+}
+
+// jumpLabel parses the optional label of `break` or `continue`, the label must name an enclosing loop.
+func (p *parser) jumpLabel() (label *token.Token, ok bool) {
+	if !p.match(token.IDENTIFIER) {
+		return nil, true
+	}
+	label = p.previous()
+	if !slices.Contains(p.labels, label.Lexeme) {
+		p.reportFatalErrorStmtToken(label, loxerrors.ErrParseUndefinedLabelError(label.Lexeme))
+		return nil, false
+	}
+	return label, true
+}
+
 func (p *parser) whileStatement() Stmt {
 	if !p.match(token.LEFT_PAREN) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftParentWhileToken)
@@ -309,6 +593,9 @@ func (p *parser) forStatement() Stmt {
 	if !p.match(token.LEFT_PAREN) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedLeftParentForToken)
 	}
+	if p.check(token.VAR) && p.checkNext(token.IDENTIFIER) && p.tokens[p.current+2].Type == token.IN {
+		return p.forEachStatement()
+	}
 
 	var initializer Stmt
 	switch {
@@ -347,37 +634,77 @@ func (p *parser) forStatement() Stmt {
 	return &StmtFor{Initializer: initializer, Condition: condition, Increment: increment, Body: body}
 }
 
+// forEachStatement parses the rest of `for (var name in iterable) body`.
+func (p *parser) forEachStatement() Stmt {
+	p.advance() // var
+	p.advance()
+	name := p.previous()
+	p.advance()
+	in := p.previous()
+	iterable := p.expression()
+	if !p.match(token.RIGHT_PAREN) {
+		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedRightParentForToken)
+	}
+
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+	body := p.statement()
+
+	return &StmtForEach{Name: name, In: in, Iterable: iterable, Body: body}
+}
+
 func (p *parser) breakStatement() Stmt {
+	keyword := p.previous()
 	if p.loopDepth == 0 {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseBreakOutsideLoop)
 	}
+	label, ok := p.jumpLabel()
+	if !ok {
+		return nilStmt
+	}
 	if !p.match(token.SEMICOLON) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedSemicolonTokenAfterBreak)
 	}
-	return &StmtBreak{}
+	return &StmtBreak{Keyword: keyword, Label: label}
 }
 
 func (p *parser) continueStatement() Stmt {
+	keyword := p.previous()
 	if p.loopDepth == 0 {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseContinueOutsideLoop)
 	}
+	label, ok := p.jumpLabel()
+	if !ok {
+		return nilStmt
+	}
 	if !p.match(token.SEMICOLON) {
 		return p.reportFatalErrorStmt(loxerrors.ErrParseExpectedSemicolonTokenAfterContinue)
 	}
-	return &StmtContinue{}
+	return &StmtContinue{Keyword: keyword, Label: label}
 }
 
-func (p *parser) blockStatement() []Stmt {
+// blockStatement parses the statements up to the closing brace into the block,
+// the comments before the brace (of the empty block too) are the block closing comments.
+func (p *parser) blockStatement(block *[]Stmt) {
+	p.exprDepth++
+	defer func() { p.exprDepth-- }()
+	if p.exprDepth > p.maxExprDepth {
+		p.reportFatalErrorStmtList(loxerrors.ErrParseBlockTooDeep)
+		return
+	}
+
 	var stmts []Stmt
 	for !p.check(token.RIGHT_BRACE) && !p.isDone() {
 		stmts = append(stmts, p.declaration())
 	}
+	addComments(p.comments.closing, any(block), p.takeComments(p.current))
 
 	if !p.match(token.RIGHT_BRACE) {
-		return p.reportFatalErrorStmtList(loxerrors.ErrParseExpectedRightCurlyBlockToken)
+		p.reportFatalErrorStmtList(loxerrors.ErrParseExpectedRightCurlyBlockToken)
+		return
 	}
 
-	return stmts
+	*block = append(*block, stmts...)
 }
 
 func (p *parser) expressionStatement() Stmt {
@@ -389,6 +716,12 @@ func (p *parser) expressionStatement() Stmt {
 }
 
 func (p *parser) expression() Expr {
+	p.exprDepth++
+	defer func() { p.exprDepth-- }()
+	if p.exprDepth > p.maxExprDepth {
+		return p.reportFatalErrorExpr(loxerrors.ErrParseExpressionTooDeep)
+	}
+
 	return p.assignment()
 }
 
@@ -401,14 +734,13 @@ func (p *parser) assignment() Expr {
 
 		switch v := expr.(type) {
 		case *ExprVariable:
-			return &ExprAssign{Name: v.Name, Value: value}
+			return &ExprAssign{Name: v.Name, Value: inferFunctionName(v.Name, value)}
 		case *ExprGet:
-			return &ExprSet{Instance: v.Instance, Name: v.Name, Value: value}
+			return &ExprSet{Instance: v.Instance, Name: v.Name, Value: inferFunctionName(v.Name, value)}
 		default:
+			// parenthesized targets, e.g. `(a) = 1` or `(a.b) = 1`, are not assignable either.
 			p.reportErrorExprToken(equals, loxerrors.ErrParseInvalidAssignmentTarget)
 		}
-
-		p.reportErrorExprToken(equals, loxerrors.ErrParseInvalidAssignmentTarget)
 	}
 
 	return expr
@@ -417,7 +749,7 @@ func (p *parser) assignment() Expr {
 func (p *parser) logicOr() Expr {
 	expr := p.logicAnd()
 
-	for p.match(token.OR) {
+	for p.anyMatch(token.OR, token.XOR) {
 		operator := p.previous()
 		right := p.logicAnd()
 		expr = &ExprLogical{Left: expr, Operator: operator, Right: right}
@@ -441,10 +773,14 @@ func (p *parser) logicAnd() Expr {
 func (p *parser) equality() Expr {
 	expr := p.comparison()
 
-	for p.anyMatch(token.BANG_EQUAL, token.EQUAL_EQUAL) {
+	for p.anyMatch(token.BANG_EQUAL, token.EQUAL_EQUAL, token.IS) {
 		operator := p.previous()
 		right := p.comparison()
-		expr = &ExprBinary{Left: expr, Operator: operator, Right: right}
+		if operator.Type == token.IS {
+			expr = &ExprTypeCheck{Instance: expr, Keyword: operator, Class: right}
+		} else {
+			expr = &ExprBinary{Left: expr, Operator: operator, Right: right}
+		}
 	}
 
 	return expr
@@ -489,11 +825,36 @@ func (p *parser) factor() Expr {
 func (p *parser) unary() Expr {
 	if p.anyMatch(token.BANG, token.MINUS) {
 		operator := p.previous()
+		// the prefix operators chain, e.g. `!!!a`, nests without the expression
+		p.exprDepth++
+		defer func() { p.exprDepth-- }()
+		if p.exprDepth > p.maxExprDepth {
+			return p.reportFatalErrorExpr(loxerrors.ErrParseExpressionTooDeep)
+		}
 		right := p.unary()
 		return &ExprUnary{Operator: operator, Right: right}
 	}
 
-	return p.call()
+	return p.power()
+}
+
+// power parses the right associative `**`, binding tighter than the unary operators on its left: -2 ** 2 is -(2 ** 2).
+func (p *parser) power() Expr {
+	expr := p.call()
+
+	if p.match(token.STAR_STAR) {
+		operator := p.previous()
+		// the right associative chain, e.g. `2 ** 2 ** 2`, nests without the expression
+		p.exprDepth++
+		defer func() { p.exprDepth-- }()
+		if p.exprDepth > p.maxExprDepth {
+			return p.reportFatalErrorExpr(loxerrors.ErrParseExpressionTooDeep)
+		}
+		right := p.unary()
+		expr = &ExprBinary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
 }
 
 func (p *parser) call() Expr {
@@ -515,9 +876,57 @@ forloop:
 		}
 	}
 
+	if operator := p.postfixOperator(); operator != nil {
+		switch expr.(type) {
+		case *ExprVariable, *ExprGet:
+			return &ExprPostfix{Target: expr, Operator: operator}
+		default:
+			p.reportErrorExprToken(operator, loxerrors.ErrParseInvalidAssignmentTarget)
+		}
+	}
+
 	return expr
 }
 
+// postfixOperator consumes the postfix `++` or `--`, two adjacent `+` or `-` tokens not followed by an operand,
+// other than the minus.
+// The scanner keeps them apart, so `a--1` is still the subtraction of the negated number a - (-1).
+func (p *parser) postfixOperator() *token.Token {
+	if p.current+2 >= len(p.tokens) {
+		return nil
+	}
+	first, second, next := p.tokens[p.current], p.tokens[p.current+1], p.tokens[p.current+2]
+	if first.Type != token.PLUS && first.Type != token.MINUS {
+		return nil
+	}
+	if second.Type != first.Type || second.Line != first.Line || second.Column != first.Column+1 {
+		return nil
+	}
+	// the following minus is the binary operator, `i-- - 1` and `a---b` keep the decrement
+	if next.Type != token.MINUS && startsOperand(next.Type) {
+		return nil
+	}
+
+	p.advance()
+	p.advance()
+	if first.Type == token.PLUS {
+		return token.NewTokenHeap(token.PLUS_PLUS, "++", nil, first.Line, first.Column)
+	}
+	return token.NewTokenHeap(token.MINUS_MINUS, "--", nil, first.Line, first.Column)
+}
+
+// startsOperand reports whether the token can start the unary expression.
+func startsOperand(tokenType token.TokenType) bool {
+	switch tokenType { //nolint:exhaustive // the rest can't start an operand
+	case token.BANG, token.MINUS, token.NUMBER, token.STRING, token.IDENTIFIER,
+		token.TRUE, token.FALSE, token.NIL, token.THIS, token.SUPER, token.FUN,
+		token.LEFT_PAREN, token.LEFT_BRACKET, token.LEFT_BRACE:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *parser) finishCall(callee Expr) Expr {
 	var args []Expr
 	if !p.check(token.RIGHT_PAREN) {
@@ -542,22 +951,22 @@ func (p *parser) finishCall(callee Expr) Expr {
 
 func (p *parser) primary() Expr { //nolint:cyclop // it's expected
 	if p.match(token.FALSE) {
-		return &ExprLiteral{Value: false}
+		return &ExprLiteral{Value: false, Token: p.previous()}
 	}
 	if p.match(token.TRUE) {
-		return &ExprLiteral{Value: true}
+		return &ExprLiteral{Value: true, Token: p.previous()}
 	}
 	if p.match(token.NIL) {
-		return &ExprLiteral{Value: nil}
+		return &ExprLiteral{Value: nil, Token: p.previous()}
 	}
 	if p.check(token.FUN) && !p.checkNext(token.IDENTIFIER) {
 		p.advance()
-		return p.functionBody("function")
+		return p.functionBody("function", true)
 	}
 
 	if p.anyMatch(token.NUMBER, token.STRING) {
 		tok := p.previous()
-		return &ExprLiteral{Value: tok.Literal}
+		return &ExprLiteral{Value: tok.Literal, Token: tok}
 	}
 
 	if p.match(token.SUPER) {
@@ -584,9 +993,55 @@ func (p *parser) primary() Expr { //nolint:cyclop // it's expected
 		return &ExprVariable{Name: tok}
 	}
 
+	if p.match(token.LEFT_BRACKET) {
+		return p.array()
+	}
+
+	// in the expression position the braces are the map literal, not the block
+	if p.match(token.LEFT_BRACE) {
+		return p.mapLiteral()
+	}
+
 	return p.grouping()
 }
 
+func (p *parser) array() Expr {
+	var elements []Expr
+	for !p.check(token.RIGHT_BRACKET) && !p.isDone() {
+		elements = append(elements, p.expression())
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	if !p.match(token.RIGHT_BRACKET) {
+		return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedRightBracketToken)
+	}
+
+	return &ExprArray{Elements: elements}
+}
+
+func (p *parser) mapLiteral() Expr {
+	brace := p.previous()
+	var keys, values []Expr
+	for !p.check(token.RIGHT_BRACE) && !p.isDone() {
+		keys = append(keys, p.expression())
+		if !p.match(token.COLON) {
+			return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedColonAfterMapKey)
+		}
+		values = append(values, p.expression())
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	if !p.match(token.RIGHT_BRACE) {
+		return p.reportFatalErrorExpr(loxerrors.ErrParseExpectedRightBraceAfterMapEntries)
+	}
+
+	return &ExprMap{Brace: brace, Keys: keys, Values: values}
+}
+
 func (p *parser) grouping() Expr {
 	if p.match(token.LEFT_PAREN) {
 		expr := p.expression()
@@ -599,6 +1054,30 @@ func (p *parser) grouping() Expr {
 	return p.reportFatalErrorExpr(loxerrors.ErrParseUnexpectedToken)
 }
 
+// takeComments returns the comments up to the token at the index (preceding it), not associated yet.
+func (p *parser) takeComments(index int) []token.Token {
+	if p.pending == nil {
+		return nil
+	}
+	var comments []token.Token
+	for ; p.nextComment <= index; p.nextComment++ {
+		comments = append(comments, p.pending[p.nextComment]...)
+	}
+	return comments
+}
+
+// inlineComment associates the comment following the statement on the same line with it.
+func (p *parser) inlineComment(stmt Stmt) {
+	if stmt == nilStmt || p.current == 0 || p.nextComment > p.current {
+		return
+	}
+	comments := p.pending[p.current]
+	if len(comments) > 0 && comments[0].Line == p.previous().Line {
+		p.comments.inline[stmt] = comments[0]
+		p.pending[p.current] = comments[1:]
+	}
+}
+
 func (p *parser) anyMatch(types ...token.TokenType) bool {
 	for _, t := range types {
 		if p.check(t) {
@@ -663,7 +1142,7 @@ func (p *parser) reportFatalErrorStmt(err error) Stmt {
 
 func (p *parser) reportFatalErrorStmtToken(tok *token.Token, err error) Stmt {
 	// do not overwrite present error.
-	// preserves the original error and bubbles up to return in Parse() with .err
+	// preserves the original error and bubbles up to return in Parse() with .errs
 	if p.panic == nil {
 		p.fatal(loxerrors.NewParseError(tok, err))
 	}
@@ -672,7 +1151,7 @@ func (p *parser) reportFatalErrorStmtToken(tok *token.Token, err error) Stmt {
 
 func (p *parser) reportFatalErrorStmtList(err error) []Stmt {
 	// do not overwrite present error.
-	// preserves the original error and bubbles up to return in Parse() with .err
+	// preserves the original error and bubbles up to return in Parse() with .errs
 	if p.panic == nil {
 		p.fatal(loxerrors.NewParseError(p.peek(), err))
 	}
@@ -685,7 +1164,7 @@ func (p *parser) reportFatalErrorExpr(err error) Expr {
 
 func (p *parser) reportFatalErrorExprToken(tok *token.Token, err error) Expr {
 	// do not overwrite present error.
-	// preserves the original error and bubbles up to return in Parse() with .err
+	// preserves the original error and bubbles up to return in Parse() with .errs
 	if p.panic == nil {
 		p.fatal(loxerrors.NewParseError(tok, err))
 	}
@@ -702,12 +1181,12 @@ func (p *parser) reportErrorExprToken(tok *token.Token, err error) {
 
 func (p *parser) fatal(err error) {
 	p.panic = err
-	p.err = err
+	p.errs = append(p.errs, err)
 	p.reporter.ReportPanic(err)
 }
 
 func (p *parser) error(err error) {
-	p.err = err
+	p.errs = append(p.errs, err)
 	p.reporter.ReportError(err)
 }
 
@@ -723,11 +1202,14 @@ func (p *parser) synchronize() {
 		case token.CLASS,
 			token.FUN,
 			token.VAR,
+			token.CONST,
 			token.FOR,
 			token.IF,
 			token.WHILE,
 			token.PRINT,
-			token.RETURN:
+			token.RETURN,
+			token.TRY,
+			token.THROW:
 			return
 		}
 
@@ -0,0 +1,306 @@
+package parser_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+	"github.com/leonardinius/golox/internal/token"
+)
+
+func TestParseReportsAllErrors(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		reported string
+	}{
+		{
+			name:  "two statements",
+			input: "var = 1;\nprint 2;\nprint 1 +;",
+			reported: "FATAL [line 1] Error at '=' (col 5): Expect variable name.\n" +
+				"FATAL [line 3] Error at ';' (col 10): Expect expression.\n",
+		},
+		{
+			name:  "function and class",
+			input: "fun f( { }\nvar x = 1;\nclass { }",
+			reported: "FATAL [line 1] Error at '{' (col 8): Expect parameter name.\n" +
+				"FATAL [line 3] Error at '{' (col 7): Expect class name.\n",
+		},
+		{
+			name:  "nested blocks",
+			input: "{\n  var = 1;\n  {\n    print;\n  }\n}",
+			reported: "FATAL [line 2] Error at '=' (col 7): Expect variable name.\n" +
+				"FATAL [line 4] Error at ';' (col 10): Expect expression.\n",
+		},
+		{
+			name:  "arrow function",
+			input: "var f = fun (x) => ;\nfun g(x) => x;",
+			reported: "FATAL [line 1] Error at ';' (col 20): Expect expression.\n" +
+				"FATAL [line 2] Error at '=>' (col 10): Expect '{' before function body.\n",
+		},
+		{
+			name:     "same line",
+			input:    "var a = 1; print a; print (a; var b = 2;",
+			reported: "FATAL [line 1] Error at ';' (col 29): Expect ')' after expression.\n",
+		},
+		{
+			name:  "same line errors",
+			input: "var a = 1; var = 2; print a; print a a;",
+			reported: "FATAL [line 1] Error at '=' (col 16): Expect variable name.\n" +
+				"FATAL [line 1] Error at 'a' (col 38): Expect ';' after print value.\n",
+		},
+		{
+			name:  "fatal and non fatal",
+			input: "1 = 2;\nprint ;",
+			reported: "ERR [line 1] Error at '=' (col 3): Invalid assignment target.\n" +
+				"FATAL [line 2] Error at ';' (col 7): Expect expression.\n",
+		},
+		{
+			name:     "rest parameter not last",
+			input:    "fun f(...a, b) {}",
+			reported: "FATAL [line 1] Error at 'a' (col 10): Rest parameter must be last.\n",
+		},
+		{
+			name:  "loop labels",
+			input: "outer: while (true) break inner;\nouter: print 1;\nwhile (true) { fun f() { continue outer; } }\nouter: for (;;) outer: while (true) break;",
+			reported: "FATAL [line 1] Error at 'inner' (col 27): Undefined label 'inner'.\n" +
+				"FATAL [line 2] Error at 'print' (col 8): Expect 'while' or 'for' after label.\n" +
+				"FATAL [line 3] Error at 'outer' (col 35): Undefined label 'outer'.\n" +
+				"ERR [line 4] Error at 'outer' (col 17): Label 'outer' is already defined.\n",
+		},
+		{
+			name:  "duplicate loop label",
+			input: "outer: while (true) { outer: while (true) {} break; }\nprint 1 +;",
+			reported: "ERR [line 1] Error at 'outer' (col 23): Label 'outer' is already defined.\n" +
+				"FATAL [line 2] Error at ';' (col 10): Expect expression.\n",
+		},
+		{
+			name:     "map literal missing colon",
+			input:    `var m = {"a" 1};`,
+			reported: "FATAL [line 1] Error at '1' (col 14): Expect ':' after map key.\n",
+		},
+		{
+			name:     "map literal missing brace",
+			input:    `var m = {"a": 1;`,
+			reported: "FATAL [line 1] Error at ';' (col 16): Expect '}' after map entries.\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reported := new(strings.Builder)
+			reporter := loxerrors.NewErrReporter(reported)
+			tokens, err := scanner.NewScanner(tc.input, reporter).Scan()
+			require.NoError(t, err)
+
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.ErrorIs(t, err, loxerrors.ErrParseError)
+			assert.Nil(t, stmts)
+			assert.Equal(t, tc.reported, reported.String())
+		})
+	}
+}
+
+func TestParseReportsFatalPrefix(t *testing.T) {
+	t.Parallel()
+
+	reported := new(strings.Builder)
+	reporter := loxerrors.NewErrReporter(reported)
+	tokens, err := scanner.NewScanner("(a) = 1;\nprint (;", reporter).Scan()
+	require.NoError(t, err)
+
+	_, err = parser.NewParser(tokens, reporter).Parse()
+	require.ErrorIs(t, err, loxerrors.ErrParseError)
+	assert.Equal(t, "ERR [line 1] Error at '=' (col 5): Invalid assignment target.\n"+
+		"FATAL [line 2] Error at ';' (col 8): Expect expression.\n", reported.String())
+}
+
+func TestParseExpressionTooDeep(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		options  []parser.ParserOption
+		reported string
+	}{
+		{
+			name:     "groupings",
+			input:    "print " + strings.Repeat("(", 100_000) + "1" + strings.Repeat(")", 100_000) + ";",
+			reported: "FATAL [line 1] Error at '(' (col 1007): Expression nesting is too deep.\n",
+		},
+		{
+			name:     "unary",
+			input:    "print " + strings.Repeat("!", 100_000) + "1;",
+			reported: "FATAL [line 1] Error at '!' (col 1007): Expression nesting is too deep.\n",
+		},
+		{
+			name:     "power",
+			input:    "print " + strings.Repeat("1 ** ", 100_000) + "1;",
+			reported: "FATAL [line 1] Error at '1' (col 5007): Expression nesting is too deep.\n",
+		},
+		{
+			name:  "blocks",
+			input: strings.Repeat("{", 100_000) + strings.Repeat("}", 100_000),
+			// the recovery skips to the end, the outer blocks are left unclosed
+			reported: "FATAL [line 1] Error at '{' (col 1002): Block nesting is too deep.\n" +
+				"FATAL [line 1] Error at end (col 200001): Expect '}' after block.\n",
+		},
+		{
+			name:     "arrays",
+			input:    strings.Repeat("[", 100_000) + strings.Repeat("]", 100_000) + ";",
+			options:  []parser.ParserOption{parser.WithMaxExpressionDepth(10)},
+			reported: "FATAL [line 1] Error at '[' (col 11): Expression nesting is too deep.\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reported := new(strings.Builder)
+			reporter := loxerrors.NewErrReporter(reported)
+			tokens, err := scanner.NewScanner(tc.input, reporter).Scan()
+			require.NoError(t, err)
+
+			_, err = parser.NewParser(tokens, reporter, tc.options...).Parse()
+			require.ErrorIs(t, err, loxerrors.ErrParseError)
+			assert.Equal(t, tc.reported, reported.String())
+		})
+	}
+
+	reporter := loxerrors.NewCollectingReporter()
+	tokens, err := scanner.NewScanner(strings.Repeat("(", 900)+"1"+strings.Repeat(")", 900)+";", reporter).Scan()
+	require.NoError(t, err)
+	_, err = parser.NewParser(tokens, reporter).Parse()
+	require.NoError(t, err, "the nesting within the default limit")
+}
+
+func TestParseCollectsErrors(t *testing.T) {
+	t.Parallel()
+
+	reporter := loxerrors.NewCollectingReporter()
+	tokens, err := scanner.NewScanner("var = 1;\nprint 2;\nprint 1 +;\n(a) = 1;", reporter).Scan()
+	require.NoError(t, err)
+
+	_, err = parser.NewParser(tokens, reporter).Parse()
+	require.ErrorIs(t, err, loxerrors.ErrParseError)
+
+	expected := []struct {
+		cause        error
+		line, column int
+	}{
+		{cause: loxerrors.ErrParseUnexpectedVariableName, line: 1, column: 5},
+		{cause: loxerrors.ErrParseUnexpectedToken, line: 3, column: 10},
+		{cause: loxerrors.ErrParseInvalidAssignmentTarget, line: 4, column: 5},
+	}
+	errs := reporter.All()
+	require.Len(t, errs, len(expected))
+	for index, e := range expected {
+		var parseErr *loxerrors.ParserError
+		require.ErrorAs(t, errs[index], &parseErr)
+		require.ErrorIs(t, parseErr, e.cause)
+		assert.Equal(t, e.line, parseErr.Token().Line)
+		assert.Equal(t, e.column, parseErr.Token().Column)
+	}
+	assert.Empty(t, reporter.Warnings)
+}
+
+func TestParseInvalidAssignmentTarget(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		reported string
+	}{
+		{name: "grouped variable", input: "(a) = 1;", reported: "ERR [line 1] Error at '=' (col 5): Invalid assignment target.\n"},
+		{name: "grouped get", input: "(a.b) = 1;", reported: "ERR [line 1] Error at '=' (col 7): Invalid assignment target.\n"},
+		{name: "nested grouped get", input: "((a.b.c)) = 1;", reported: "ERR [line 1] Error at '=' (col 11): Invalid assignment target.\n"},
+		{name: "grouped array", input: "([a]) = 1;", reported: "ERR [line 1] Error at '=' (col 7): Invalid assignment target.\n"},
+		{name: "call", input: "a.b() = 1;", reported: "ERR [line 1] Error at '=' (col 7): Invalid assignment target.\n"},
+		{name: "postfix grouped", input: "(a)++;", reported: "ERR [line 1] Error at '++' (col 4): Invalid assignment target.\n"},
+		{name: "postfix call", input: "a()--;", reported: "ERR [line 1] Error at '--' (col 4): Invalid assignment target.\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reported := new(strings.Builder)
+			reporter := loxerrors.NewErrReporter(reported)
+			tokens, err := scanner.NewScanner(tc.input, reporter).Scan()
+			require.NoError(t, err)
+
+			_, err = parser.NewParser(tokens, reporter).Parse()
+			require.ErrorIs(t, err, loxerrors.ErrParseError)
+			assert.Equal(t, tc.reported, reported.String())
+		})
+	}
+}
+
+func TestNewParserChecked(t *testing.T) {
+	t.Parallel()
+
+	reporter := loxerrors.NewErrReporter(io.Discard)
+	testcases := []struct {
+		name   string
+		tokens []token.Token
+		err    error
+	}{
+		{"nil", nil, loxerrors.ErrParseTokensEmpty},
+		{"empty", []token.Token{}, loxerrors.ErrParseTokensEmpty},
+		{"missing eof", []token.Token{token.NewToken(token.NIL, "nil", nil, 1, 1)}, loxerrors.ErrParseTokensMissingEOF},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := parser.NewParserChecked(tc.tokens, reporter)
+			require.ErrorIs(t, err, tc.err)
+			assert.Nil(t, p)
+			assert.PanicsWithError(t, tc.err.Error(), func() { parser.NewParser(tc.tokens, reporter) })
+		})
+	}
+
+	p, err := parser.NewParserChecked([]token.Token{
+		token.NewToken(token.NIL, "nil", nil, 1, 1),
+		token.NewToken(token.EOF, "", nil, 1, 4),
+	}, reporter)
+	require.NoError(t, err)
+	expr, err := p.ParseExpression()
+	require.NoError(t, err)
+	assert.IsType(t, &parser.ExprLiteral{}, expr)
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(``)
+	f.Add(`print 1 + 2 * 3 ** 4 - -5 xor !true;`)
+	f.Add("var a = \"s\"; // comment\n/* block */ a = a + \"t\";")
+	f.Add(`fun f(a, ...rest) { return a xor rest.length > 0; } f(1, 2, 3); var g = fun (x) => x * 2;`)
+	f.Add(`class A < B { var n = 0; init(x) { this.x = x; } class make() { return A(1); } area { return super.area * 2; } } print A(1) is B;`)
+	f.Add(`outer: for (var i = 0; i < 2; i++) { for (var x in [1, {i: "v", "k": [x]}]) continue outer; }`)
+	f.Add(`const c = 1; ;; try { throw c; } catch (e) { print e, c; } finally { a.b--; } ok: while (true) break ok;`)
+	f.Add(`a(b(c(d(`)
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, options := range [][]scanner.ScannerOption{nil, {scanner.WithComments()}} {
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			tokens, err := scanner.NewScanner(input, reporter, options...).Scan()
+			if err != nil {
+				return
+			}
+			p := parser.NewParser(tokens, reporter)
+			if stmts, err := p.Parse(); err == nil {
+				_ = parser.Format(stmts, parser.WithComments(p.Comments()))
+			}
+			_, _ = parser.NewParser(tokens, reporter).ParseExpression()
+		}
+	})
+}
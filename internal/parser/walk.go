@@ -0,0 +1,243 @@
+package parser
+
+// Walk traverses the syntax tree depth first, the node is either Expr, Stmt or []Stmt.
+// The fn is called for every Expr and Stmt before their children; returning false skips the children.
+func Walk(node any, fn func(node any) bool) {
+	(&walker{fn: fn}).walk(node)
+}
+
+type walker struct {
+	fn func(node any) bool
+}
+
+func (w *walker) walk(nodes ...any) {
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case Expr:
+			if w.fn(node) {
+				_, _ = node.Accept(w)
+			}
+		case Stmt:
+			if w.fn(node) {
+				_, _ = node.Accept(w)
+			}
+		case []Expr:
+			for _, expr := range node {
+				w.walk(expr)
+			}
+		case []Stmt:
+			for _, stmt := range node {
+				w.walk(stmt)
+			}
+		}
+	}
+}
+
+// VisitExprArray implements ExprVisitor.
+func (w *walker) VisitExprArray(exprArray *ExprArray) (any, error) {
+	w.walk(exprArray.Elements)
+	return nil, nil
+}
+
+// VisitExprAssign implements ExprVisitor.
+func (w *walker) VisitExprAssign(exprAssign *ExprAssign) (any, error) {
+	w.walk(exprAssign.Value)
+	return nil, nil
+}
+
+// VisitExprBinary implements ExprVisitor.
+func (w *walker) VisitExprBinary(exprBinary *ExprBinary) (any, error) {
+	w.walk(exprBinary.Left, exprBinary.Right)
+	return nil, nil
+}
+
+// VisitExprCall implements ExprVisitor.
+func (w *walker) VisitExprCall(exprCall *ExprCall) (any, error) {
+	w.walk(exprCall.Callee, exprCall.Arguments)
+	return nil, nil
+}
+
+// VisitExprFunction implements ExprVisitor.
+func (w *walker) VisitExprFunction(exprFunction *ExprFunction) (any, error) {
+	w.walk(exprFunction.Body)
+	return nil, nil
+}
+
+// VisitExprGet implements ExprVisitor.
+func (w *walker) VisitExprGet(exprGet *ExprGet) (any, error) {
+	w.walk(exprGet.Instance)
+	return nil, nil
+}
+
+// VisitExprGrouping implements ExprVisitor.
+func (w *walker) VisitExprGrouping(exprGrouping *ExprGrouping) (any, error) {
+	w.walk(exprGrouping.Expression)
+	return nil, nil
+}
+
+// VisitExprLiteral implements ExprVisitor.
+func (w *walker) VisitExprLiteral(exprLiteral *ExprLiteral) (any, error) {
+	return nil, nil
+}
+
+// VisitExprLogical implements ExprVisitor.
+func (w *walker) VisitExprLogical(exprLogical *ExprLogical) (any, error) {
+	w.walk(exprLogical.Left, exprLogical.Right)
+	return nil, nil
+}
+
+// VisitExprMap implements ExprVisitor.
+func (w *walker) VisitExprMap(exprMap *ExprMap) (any, error) {
+	for index, key := range exprMap.Keys {
+		w.walk(key, exprMap.Values[index])
+	}
+	return nil, nil
+}
+
+// VisitExprSet implements ExprVisitor.
+func (w *walker) VisitExprSet(exprSet *ExprSet) (any, error) {
+	w.walk(exprSet.Instance, exprSet.Value)
+	return nil, nil
+}
+
+// VisitExprSuper implements ExprVisitor.
+func (w *walker) VisitExprSuper(exprSuper *ExprSuper) (any, error) {
+	return nil, nil
+}
+
+// VisitExprThis implements ExprVisitor.
+func (w *walker) VisitExprThis(exprThis *ExprThis) (any, error) {
+	return nil, nil
+}
+
+// VisitExprTypeCheck implements ExprVisitor.
+func (w *walker) VisitExprTypeCheck(exprTypeCheck *ExprTypeCheck) (any, error) {
+	w.walk(exprTypeCheck.Instance, exprTypeCheck.Class)
+	return nil, nil
+}
+
+// VisitExprPostfix implements ExprVisitor.
+func (w *walker) VisitExprPostfix(exprPostfix *ExprPostfix) (any, error) {
+	w.walk(exprPostfix.Target)
+	return nil, nil
+}
+
+// VisitExprUnary implements ExprVisitor.
+func (w *walker) VisitExprUnary(exprUnary *ExprUnary) (any, error) {
+	w.walk(exprUnary.Right)
+	return nil, nil
+}
+
+// VisitExprVariable implements ExprVisitor.
+func (w *walker) VisitExprVariable(exprVariable *ExprVariable) (any, error) {
+	return nil, nil
+}
+
+// VisitStmtBlock implements StmtVisitor.
+func (w *walker) VisitStmtBlock(stmtBlock *StmtBlock) (any, error) {
+	w.walk(stmtBlock.Statements)
+	return nil, nil
+}
+
+// VisitStmtClass implements StmtVisitor.
+func (w *walker) VisitStmtClass(stmtClass *StmtClass) (any, error) {
+	if stmtClass.SuperClass != nil {
+		w.walk(stmtClass.SuperClass)
+	}
+	for _, field := range stmtClass.Fields {
+		w.walk(field)
+	}
+	for _, method := range stmtClass.Methods {
+		w.walk(method)
+	}
+	for _, method := range stmtClass.ClassMethods {
+		w.walk(method)
+	}
+	return nil, nil
+}
+
+// VisitStmtExpression implements StmtVisitor.
+func (w *walker) VisitStmtExpression(stmtExpression *StmtExpression) (any, error) {
+	w.walk(stmtExpression.Expression)
+	return nil, nil
+}
+
+// VisitStmtFunction implements StmtVisitor.
+func (w *walker) VisitStmtFunction(stmtFunction *StmtFunction) (any, error) {
+	w.walk(stmtFunction.Fn)
+	return nil, nil
+}
+
+// VisitStmtIf implements StmtVisitor.
+func (w *walker) VisitStmtIf(stmtIf *StmtIf) (any, error) {
+	w.walk(stmtIf.Condition, stmtIf.ThenBranch, stmtIf.ElseBranch)
+	return nil, nil
+}
+
+// VisitStmtPrint implements StmtVisitor.
+func (w *walker) VisitStmtPrint(stmtPrint *StmtPrint) (any, error) {
+	w.walk(stmtPrint.Expressions)
+	return nil, nil
+}
+
+// VisitStmtReturn implements StmtVisitor.
+func (w *walker) VisitStmtReturn(stmtReturn *StmtReturn) (any, error) {
+	w.walk(stmtReturn.Value)
+	return nil, nil
+}
+
+// VisitStmtVar implements StmtVisitor.
+func (w *walker) VisitStmtVar(stmtVar *StmtVar) (any, error) {
+	w.walk(stmtVar.Initializer)
+	return nil, nil
+}
+
+// VisitStmtWhile implements StmtVisitor.
+func (w *walker) VisitStmtWhile(stmtWhile *StmtWhile) (any, error) {
+	w.walk(stmtWhile.Condition, stmtWhile.Body)
+	return nil, nil
+}
+
+// VisitStmtFor implements StmtVisitor.
+func (w *walker) VisitStmtFor(stmtFor *StmtFor) (any, error) {
+	w.walk(stmtFor.Initializer, stmtFor.Condition, stmtFor.Increment, stmtFor.Body)
+	return nil, nil
+}
+
+// VisitStmtForEach implements StmtVisitor.
+func (w *walker) VisitStmtForEach(stmtForEach *StmtForEach) (any, error) {
+	w.walk(stmtForEach.Iterable, stmtForEach.Body)
+	return nil, nil
+}
+
+// VisitStmtTry implements StmtVisitor.
+func (w *walker) VisitStmtTry(stmtTry *StmtTry) (any, error) {
+	w.walk(stmtTry.Body, stmtTry.CatchBody, stmtTry.FinallyBody)
+	return nil, nil
+}
+
+// VisitStmtThrow implements StmtVisitor.
+func (w *walker) VisitStmtThrow(stmtThrow *StmtThrow) (any, error) {
+	w.walk(stmtThrow.Value)
+	return nil, nil
+}
+
+// VisitStmtBreak implements StmtVisitor.
+func (w *walker) VisitStmtBreak(stmtBreak *StmtBreak) (any, error) {
+	return nil, nil
+}
+
+// VisitStmtContinue implements StmtVisitor.
+func (w *walker) VisitStmtContinue(stmtContinue *StmtContinue) (any, error) {
+	return nil, nil
+}
+
+// VisitStmtEmpty implements StmtVisitor.
+func (w *walker) VisitStmtEmpty(stmtEmpty *StmtEmpty) (any, error) {
+	return nil, nil
+}
+
+var (
+	_ ExprVisitor = (*walker)(nil)
+	_ StmtVisitor = (*walker)(nil)
+)
@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
+	"github.com/leonardinius/golox/internal/parser"
+	"github.com/leonardinius/golox/internal/scanner"
+)
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		binaries int
+		prune    int // binaries outside of the functions
+	}{
+		{"expression", `1 + 2 * 3;`, 2, 2},
+		{"no binaries", `print a and b;`, 0, 0},
+		{"statements", `var a = 1 + 1; if (a > 1) print a - 1; else while (a < 3) a = a + 1;`, 5, 5},
+		{"loops", `for (var i = 0; i < 3; i = i + 1) for (var x in [i * 2]) print x;`, 3, 3},
+		{"functions", `fun f(a) { return a + 1; } var g = fun (b) { return b * 2; }; f(1 + 2);`, 3, 1},
+		{"class", `class A < B { var x = 1 + 2; m() { return this.x * 2; } class s() { return -1 / 1; } }`, 3, 1},
+		{"try", `try { throw 1 + 1; } catch (e) { print e == 2; } finally { print 3 >= 2; }`, 3, 3},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reporter := loxerrors.NewErrReporter(io.Discard)
+			tokens, err := scanner.NewScanner(tc.input, reporter).Scan()
+			require.NoError(t, err)
+			stmts, err := parser.NewParser(tokens, reporter).Parse()
+			require.NoError(t, err)
+
+			binaries := 0
+			parser.Walk(stmts, func(node any) bool {
+				if _, ok := node.(*parser.ExprBinary); ok {
+					binaries++
+				}
+				return true
+			})
+			assert.Equal(t, tc.binaries, binaries)
+
+			pruned := 0
+			parser.Walk(stmts, func(node any) bool {
+				switch node.(type) {
+				case *parser.ExprBinary:
+					pruned++
+				case *parser.ExprFunction:
+					return false
+				}
+				return true
+			})
+			assert.Equal(t, tc.prune, pruned)
+		})
+	}
+}
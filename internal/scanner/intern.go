@@ -0,0 +1,44 @@
+package scanner
+
+import "github.com/leonardinius/golox/internal/token"
+
+// fixedLexemes are the interned lexemes of the tokens always spelled the same (punctuation and keywords),
+// indexed by the token type; empty for the tokens with the source dependent lexemes, e.g. identifiers.
+// The scanned tokens share the interned strings instead of allocating a copy of the source each.
+var fixedLexemes = internLexemes(map[token.TokenType]string{
+	token.LEFT_PAREN:    "(",
+	token.RIGHT_PAREN:   ")",
+	token.LEFT_BRACE:    "{",
+	token.RIGHT_BRACE:   "}",
+	token.LEFT_BRACKET:  "[",
+	token.RIGHT_BRACKET: "]",
+	token.COLON:         ":",
+	token.COMMA:         ",",
+	token.DOT:           ".",
+	token.MINUS:         "-",
+	token.PLUS:          "+",
+	token.SEMICOLON:     ";",
+	token.SLASH:         "/",
+	token.STAR:          "*",
+	token.BANG:          "!",
+	token.BANG_EQUAL:    "!=",
+	token.EQUAL:         "=",
+	token.EQUAL_EQUAL:   "==",
+	token.GREATER:       ">",
+	token.GREATER_EQUAL: ">=",
+	token.LESS:          "<",
+	token.LESS_EQUAL:    "<=",
+	token.ELLIPSIS:      "...",
+	token.ARROW:         "=>",
+	token.STAR_STAR:     "**",
+})
+
+func internLexemes(punctuation map[token.TokenType]string) (lexemes [256]string) {
+	for tokenType, lexeme := range punctuation {
+		lexemes[tokenType] = lexeme
+	}
+	for keyword, tokenType := range token.Keywords {
+		lexemes[tokenType] = keyword
+	}
+	return lexemes
+}
@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
 	"github.com/leonardinius/golox/internal/token"
@@ -11,34 +13,94 @@ type Scanner interface {
 	Scan() ([]token.Token, error)
 }
 
+// byteOrderMark is the UTF-8 encoded BOM, some editors save at the file start.
+const byteOrderMark = "\uFEFF"
+
 type scanner struct {
+	// input is the source as given, checked for the encoding errors.
+	input                string
 	source               []rune
 	tokens               []token.Token
 	start, current, line int
-	err                  error
-	reporter             loxerrors.ErrReporter
+	// lineStart is the index of the current line first rune, column is the current token start column.
+	lineStart, column int
+	err               error
+	reporter          loxerrors.ErrReporter
+	// comments are kept as COMMENT tokens.
+	comments bool
+	// lenient returns the tokens scanned despite the errors.
+	lenient bool
+}
+
+type ScannerOption func(*scanner)
+
+// WithComments keeps the comments as COMMENT tokens, the parser associates them with the statements.
+func WithComments() ScannerOption {
+	return func(s *scanner) {
+		s.comments = true
+	}
+}
+
+// WithErrorRecovery returns the tokens scanned past the errors (e.g. the unexpected characters are skipped)
+// together with the loxerrors.ErrScanError, for the tooling working with the incomplete sources.
+// The errors are reported as the non-fatal ones. The invalid UTF-8 source is still rejected.
+func WithErrorRecovery() ScannerOption {
+	return func(s *scanner) {
+		s.lenient = true
+	}
 }
 
 // NewScanner returns a new Scanner.
-func NewScanner(input string, reporter loxerrors.ErrReporter) Scanner {
-	return &scanner{source: []rune(input), start: 0, current: 0, line: 1, reporter: reporter}
+func NewScanner(input string, reporter loxerrors.ErrReporter, options ...ScannerOption) Scanner {
+	input = strings.TrimPrefix(input, byteOrderMark)
+	s := &scanner{input: input, source: []rune(input), start: 0, current: 0, line: 1, reporter: reporter}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
 }
 
 // Scan implements Scanner.
 func (s *scanner) Scan() ([]token.Token, error) {
+	if line, column, ok := invalidEncoding(s.input); ok {
+		s.report(loxerrors.NewScanError(line, column, loxerrors.ErrScanInvalidEncoding))
+		return nil, loxerrors.ErrScanError
+	}
+
 	for !s.isAtEnd() {
 		// We are at the beginning of the next lexeme.
 		s.start = s.current
+		s.column = s.current - s.lineStart + 1
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, token.NewToken(token.EOF, "", nil, s.line))
+	s.column = s.current - s.lineStart + 1
+	s.tokens = append(s.tokens, token.NewToken(token.EOF, "", nil, s.line, s.column))
 
-	if s.err != nil {
-		return nil, loxerrors.ErrScanError
+	if s.err == nil {
+		return s.tokens, nil
 	}
+	if s.lenient {
+		return s.tokens, loxerrors.ErrScanError
+	}
+	return nil, loxerrors.ErrScanError
+}
 
-	return s.tokens, nil
+// invalidEncoding returns the position of the first invalid UTF-8 sequence, e.g. of the UTF-16 encoded source.
+func invalidEncoding(input string) (line, column int, ok bool) {
+	line, column = 1, 1
+	for index := 0; index < len(input); {
+		c, size := utf8.DecodeRuneInString(input[index:])
+		if c == utf8.RuneError && size == 1 {
+			return line, column, true
+		}
+		index += size
+		column++
+		if c == '\n' {
+			line, column = line+1, 1
+		}
+	}
+	return 0, 0, false
 }
 
 func (s *scanner) isAtEnd() bool {
@@ -57,10 +119,22 @@ func (s *scanner) scanToken() {
 		s.addToken(token.LEFT_BRACE)
 	case '}':
 		s.addToken(token.RIGHT_BRACE)
+	case '[':
+		s.addToken(token.LEFT_BRACKET)
+	case ']':
+		s.addToken(token.RIGHT_BRACKET)
+	case ':':
+		s.addToken(token.COLON)
 	case ',':
 		s.addToken(token.COMMA)
 	case '.':
-		s.addToken(token.DOT)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.advance()
+			s.advance()
+			s.addToken(token.ELLIPSIS)
+		} else {
+			s.addToken(token.DOT)
+		}
 	case '-':
 		s.addToken(token.MINUS)
 	case '+':
@@ -68,11 +142,15 @@ func (s *scanner) scanToken() {
 	case ';':
 		s.addToken(token.SEMICOLON)
 	case '*':
-		s.addToken(token.STAR)
+		s.addMatchToken('*', token.STAR_STAR, token.STAR)
 	case '!':
 		s.addMatchToken('=', token.BANG_EQUAL, token.BANG)
 	case '=':
-		s.addMatchToken('=', token.EQUAL_EQUAL, token.EQUAL)
+		if s.match('>') {
+			s.addToken(token.ARROW)
+		} else {
+			s.addMatchToken('=', token.EQUAL_EQUAL, token.EQUAL)
+		}
 	case '<':
 		s.addMatchToken('=', token.LESS_EQUAL, token.LESS)
 	case '>':
@@ -80,8 +158,11 @@ func (s *scanner) scanToken() {
 	case '/':
 		if s.match('/') {
 			s.comment()
+			s.addComment(s.line)
 		} else if s.match('*') {
+			line := s.line
 			s.blockComment()
+			s.addComment(line)
 		} else {
 			s.addToken(token.SLASH)
 		}
@@ -117,6 +198,7 @@ func (s *scanner) peekNext() rune {
 func (s *scanner) advance() rune {
 	if s.source[s.current] == '\n' {
 		s.line++
+		s.lineStart = s.current + 1
 	}
 	s.current++
 	return s.source[s.current-1]
@@ -144,7 +226,22 @@ func (s *scanner) addToken(t token.TokenType) {
 }
 
 func (s *scanner) addTokenLiteral(t token.TokenType, literal any) {
-	s.tokens = append(s.tokens, token.NewToken(t, string(s.source[s.start:s.current]), literal, s.line))
+	lexeme := fixedLexemes[t]
+	if lexeme == "" {
+		lexeme = string(s.source[s.start:s.current])
+	}
+	s.appendToken(t, lexeme, literal)
+}
+
+func (s *scanner) appendToken(t token.TokenType, lexeme string, literal any) {
+	s.tokens = append(s.tokens, token.NewToken(t, lexeme, literal, s.line, s.column))
+}
+
+// addComment adds the COMMENT token, if the comments are kept; the line is the comment start line.
+func (s *scanner) addComment(line int) {
+	if s.comments {
+		s.tokens = append(s.tokens, token.NewToken(token.COMMENT, string(s.source[s.start:s.current]), nil, line, s.column))
+	}
 }
 
 func (s *scanner) comment() {
@@ -219,12 +316,12 @@ func (s *scanner) reservedOrIdentifier() {
 		s.advance()
 	}
 
-	tokenType := token.IDENTIFIER
 	name := string(s.source[s.start:s.current])
-	if _type, ok := s.reserved(name); ok {
-		tokenType = _type
+	if tokenType, ok := s.reserved(name); ok {
+		s.addToken(tokenType)
+		return
 	}
-	s.addToken(tokenType)
+	s.appendToken(token.IDENTIFIER, name, nil)
 }
 
 func (s *scanner) reserved(identifier string) (tokenType token.TokenType, ok bool) {
@@ -247,7 +344,13 @@ func (s *scanner) isAlphaNumeric(c rune) bool {
 }
 
 func (s *scanner) reportError(err error) {
-	s.report(loxerrors.NewScanError(s.line, err))
+	err = loxerrors.NewScanError(s.line, s.column, err)
+	if s.lenient {
+		s.err = err
+		s.reporter.ReportError(err)
+		return
+	}
+	s.report(err)
 }
 
 func (s *scanner) report(err error) {
@@ -2,13 +2,17 @@ package scanner_test
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/leonardinius/golox/internal/loxerrors"
 	"github.com/leonardinius/golox/internal/scanner"
+	"github.com/leonardinius/golox/internal/token"
 )
 
 func TestScanTokens(t *testing.T) {
@@ -41,6 +45,74 @@ func TestScanTokens(t *testing.T) {
 			"",
 			"",
 		},
+		{
+			"brackets",
+			"[1,]",
+			[]string{
+				`{Type: LEFT_BRACKET, Literal: <nil>, Line: 1}`,
+				`{Type: NUMBER, Literal: 1, Line: 1}`,
+				`{Type: COMMA, Literal: <nil>, Line: 1}`,
+				`{Type: RIGHT_BRACKET, Literal: <nil>, Line: 1}`,
+				`{Type: EOF, Literal: <nil>, Line: 1}`,
+			},
+			"",
+			"",
+		},
+		{
+			"star star",
+			"* ** *** /**/",
+			[]string{
+				`{Type: STAR, Literal: <nil>, Line: 1}`,
+				`{Type: STAR_STAR, Literal: <nil>, Line: 1}`,
+				`{Type: STAR_STAR, Literal: <nil>, Line: 1}`,
+				`{Type: STAR, Literal: <nil>, Line: 1}`,
+				`{Type: EOF, Literal: <nil>, Line: 1}`,
+			},
+			"",
+			"",
+		},
+		{
+			"increments",
+			"+ ++ - -- ---",
+			[]string{
+				`{Type: PLUS, Literal: <nil>, Line: 1}`,
+				`{Type: PLUS, Literal: <nil>, Line: 1}`,
+				`{Type: PLUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: MINUS, Literal: <nil>, Line: 1}`,
+				`{Type: EOF, Literal: <nil>, Line: 1}`,
+			},
+			"",
+			"",
+		},
+		{
+			"is keyword",
+			"a is B",
+			[]string{
+				`{Type: IDENTIFIER, Literal: <nil>, Line: 1}`,
+				`{Type: IS, Literal: <nil>, Line: 1}`,
+				`{Type: IDENTIFIER, Literal: <nil>, Line: 1}`,
+				`{Type: EOF, Literal: <nil>, Line: 1}`,
+			},
+			"",
+			"",
+		},
+		{
+			"arrow",
+			"= => ==",
+			[]string{
+				`{Type: EQUAL, Literal: <nil>, Line: 1}`,
+				`{Type: ARROW, Literal: <nil>, Line: 1}`,
+				`{Type: EQUAL_EQUAL, Literal: <nil>, Line: 1}`,
+				`{Type: EOF, Literal: <nil>, Line: 1}`,
+			},
+			"",
+			"",
+		},
 		{
 			"bang",
 			"!",
@@ -261,7 +333,7 @@ func TestScanTokens(t *testing.T) {
 		},
 		{
 			"reserved",
-			`and class else false for fun if nil or print return super this true var while`,
+			`and class else false for fun if nil or print return super this true var while xor`,
 			[]string{
 				`{Type: AND, Literal: <nil>, Line: 1}`,
 				`{Type: CLASS, Literal: <nil>, Line: 1}`,
@@ -279,6 +351,7 @@ func TestScanTokens(t *testing.T) {
 				`{Type: TRUE, Literal: <nil>, Line: 1}`,
 				`{Type: VAR, Literal: <nil>, Line: 1}`,
 				`{Type: WHILE, Literal: <nil>, Line: 1}`,
+				`{Type: XOR, Literal: <nil>, Line: 1}`,
 				`{Type: EOF, Literal: <nil>, Line: 1}`,
 			},
 			"",
@@ -335,3 +408,182 @@ func TestScanTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestScanColumns(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"single line",
+			"var a = 10;",
+			[]string{`VAR 1:1`, `IDENTIFIER 1:5`, `EQUAL 1:7`, `NUMBER 1:9`, `SEMICOLON 1:11`, `EOF 1:12`},
+		},
+		{
+			"reset on newline",
+			"a\n  b;\n\n\tc",
+			[]string{`IDENTIFIER 1:1`, `IDENTIFIER 2:3`, `SEMICOLON 2:4`, `IDENTIFIER 4:2`, `EOF 4:3`},
+		},
+		{
+			"two char operators",
+			"a>=b!=c",
+			[]string{`IDENTIFIER 1:1`, `GREATER_EQUAL 1:2`, `IDENTIFIER 1:4`, `BANG_EQUAL 1:5`, `IDENTIFIER 1:7`, `EOF 1:8`},
+		},
+		{
+			"runes",
+			`"⌘⌘" + x`,
+			[]string{`STRING 1:1`, `PLUS 1:6`, `IDENTIFIER 1:8`, `EOF 1:9`},
+		},
+		{
+			"comments",
+			"/* c */ a // b\nc",
+			[]string{`IDENTIFIER 1:9`, `IDENTIFIER 2:1`, `EOF 2:2`},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tokens, err := scanner.NewScanner(tc.input, loxerrors.NewErrReporter(io.Discard)).Scan()
+			assert.NoError(t, err)
+			positions := make([]string, len(tokens))
+			for i, token := range tokens {
+				positions[i] = fmt.Sprintf(`%s %d:%d`, token.Type, token.Line, token.Column)
+			}
+			assert.Equal(t, tc.expected, positions)
+		})
+	}
+}
+
+func TestScanErrorColumn(t *testing.T) {
+	t.Parallel()
+
+	stderr := &strings.Builder{}
+	_, err := scanner.NewScanner("a;\nb ⌘ c;", loxerrors.NewErrReporter(stderr)).Scan()
+	assert.ErrorIs(t, err, loxerrors.ErrScanError)
+	assert.Equal(t, "FATAL [line 2] Error (col 3): Unexpected character.\n", stderr.String())
+}
+
+func TestScanWithErrorRecovery(t *testing.T) {
+	t.Parallel()
+
+	stderr := &strings.Builder{}
+	tokens, err := scanner.NewScanner("a #;\nb ⌘ c;", loxerrors.NewErrReporter(stderr), scanner.WithErrorRecovery()).Scan()
+	require.ErrorIs(t, err, loxerrors.ErrScanError)
+	assert.Equal(t, "ERR [line 1] Error (col 3): Unexpected character.\nERR [line 2] Error (col 3): Unexpected character.\n", stderr.String())
+
+	lexemes := make([]string, len(tokens))
+	for i, tok := range tokens {
+		lexemes[i] = tok.Lexeme
+	}
+	assert.Equal(t, []string{"a", ";", "b", "c", ";", ""}, lexemes)
+	assert.Equal(t, 5, tokens[3].Column)
+}
+
+func TestScanByteOrderMark(t *testing.T) {
+	t.Parallel()
+
+	tokens, err := scanner.NewScanner("\uFEFFprint 1;", loxerrors.NewErrReporter(io.Discard)).Scan()
+	require.NoError(t, err)
+	require.Len(t, tokens, 4)
+	assert.Equal(t, token.PRINT, tokens[0].Type)
+	assert.Equal(t, 1, tokens[0].Column)
+}
+
+func TestScanInvalidEncoding(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		reported string
+	}{
+		{"utf-16 le", "\xff\xfep\x00", "FATAL [line 1] Error (col 1): Source is not valid UTF-8.\n"},
+		{"latin-1 string", "print 1;\nprint \"caf\xe9\";", "FATAL [line 2] Error (col 11): Source is not valid UTF-8.\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stderr := &strings.Builder{}
+			_, err := scanner.NewScanner(tc.input, loxerrors.NewErrReporter(stderr)).Scan()
+			require.ErrorIs(t, err, loxerrors.ErrScanError)
+			assert.Equal(t, tc.reported, stderr.String())
+		})
+	}
+}
+
+func TestScanInternedLexemes(t *testing.T) {
+	t.Parallel()
+
+	input := `( ) { } [ ] : , . - + ; / * ! != = == > >= < <= ... => ** and class var while xor name`
+	tokens, err := scanner.NewScanner(input+" "+input, loxerrors.NewErrReporter(io.Discard)).Scan()
+	require.NoError(t, err)
+
+	lexemes := strings.Fields(input)
+	require.Len(t, tokens, 2*len(lexemes)+1)
+	for i, lexeme := range lexemes {
+		first, second := tokens[i], tokens[len(lexemes)+i]
+		assert.Equal(t, lexeme, first.Lexeme)
+		assert.Equal(t, first.Type, second.Type)
+		assert.Equal(t, lexeme, second.Lexeme)
+		if first.Type == token.IDENTIFIER {
+			assert.NotSame(t, unsafe.StringData(first.Lexeme), unsafe.StringData(second.Lexeme), lexeme)
+		} else {
+			assert.Same(t, unsafe.StringData(first.Lexeme), unsafe.StringData(second.Lexeme), lexeme)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	var sb strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&sb, "fun f%d(a, b) {\n  var x = a * %d + b;\n  if (x >= 10 and x != 20) { return x; }\n  return nil;\n}\n", i, i)
+	}
+	input := sb.String()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := scanner.NewScanner(input, loxerrors.NewErrReporter(io.Discard)).Scan(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func FuzzScan(f *testing.F) {
+	f.Add(``)
+	f.Add(`var a = 1.5 + "s" ** b++; // comment`)
+	f.Add("/* unterminated\n \"string")
+	f.Add("\ufeff[x] => {k: 1} ... != >= <=")
+	f.Add("\xff\xfe@#")
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, options := range [][]scanner.ScannerOption{nil, {scanner.WithComments()}} {
+			tokens, err := scanner.NewScanner(input, loxerrors.NewErrReporter(io.Discard), options...).Scan()
+			if err == nil && (len(tokens) == 0 || tokens[len(tokens)-1].Type != token.EOF) {
+				t.Fatalf("missing EOF token: %v", tokens)
+			}
+		}
+	})
+}
+
+func TestScanWithComments(t *testing.T) {
+	t.Parallel()
+
+	tokens, err := scanner.NewScanner("/* c\n d */ a // b\nc", loxerrors.NewErrReporter(io.Discard), scanner.WithComments()).Scan()
+	assert.NoError(t, err)
+	scanned := make([]string, len(tokens))
+	for i, token := range tokens {
+		scanned[i] = fmt.Sprintf(`%s %q %d:%d`, token.Type, token.Lexeme, token.Line, token.Column)
+	}
+	assert.Equal(t, []string{
+		`COMMENT "/* c\n d */" 1:1`,
+		`IDENTIFIER "a" 2:7`,
+		`COMMENT "// b" 2:9`,
+		`IDENTIFIER "c" 3:1`,
+		`EOF "" 3:2`,
+	}, scanned)
+}
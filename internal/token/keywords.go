@@ -3,20 +3,28 @@ package token
 var Keywords = map[string]TokenType{
 	"and":      AND,
 	"break":    BREAK,
+	"catch":    CATCH,
 	"continue": CONTINUE,
 	"class":    CLASS,
+	"const":    CONST,
 	"else":     ELSE,
 	"false":    FALSE,
+	"finally":  FINALLY,
 	"for":      FOR,
 	"fun":      FUN,
 	"if":       IF,
+	"in":       IN,
+	"is":       IS,
 	"nil":      NIL,
 	"or":       OR,
 	"print":    PRINT,
 	"return":   RETURN,
 	"super":    SUPER,
 	"this":     THIS,
+	"throw":    THROW,
 	"true":     TRUE,
+	"try":      TRY,
 	"var":      VAR,
 	"while":    WHILE,
+	"xor":      XOR,
 }
@@ -10,22 +10,46 @@ type Token struct {
 	Lexeme  string
 	Literal any
 	Line    int
+	// Column is the 1-based column (in runes) of the token start, 0 if unknown.
+	Column int
 }
 
-func NewToken(t TokenType, lexeme string, literal any, line int) Token {
+func NewToken(t TokenType, lexeme string, literal any, line, column int) Token {
 	return Token{
 		Type:    t,
 		Lexeme:  lexeme,
 		Literal: literal,
 		Line:    line,
+		Column:  column,
 	}
 }
 
-func NewTokenHeap(t TokenType, lexeme string, literal any, line int) *Token {
-	tt := NewToken(t, lexeme, literal, line)
+func NewTokenHeap(t TokenType, lexeme string, literal any, line, column int) *Token {
+	tt := NewToken(t, lexeme, literal, line, column)
 	return &tt
 }
 
+// Position returns the token position, e.g. "line 1, col 5", the column is omitted if unknown.
+func (t *Token) Position() string {
+	return Position(t.Line, t.Column)
+}
+
+// Position formats the source position, e.g. "line 1, col 5", the column is omitted if unknown.
+func Position(line, column int) string {
+	if column <= 0 {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("line %d, col %d", line, column)
+}
+
+// ColumnSuffix formats the column of the error messages, e.g. " (col 5)", empty if unknown.
+func ColumnSuffix(column int) string {
+	if column <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (col %d)", column)
+}
+
 // String implements fmt.Stringer.
 func (t Token) String() string {
 	return fmt.Sprintf("%s %s %v", t.Type, t.Lexeme, t.Literal)
@@ -33,7 +57,7 @@ func (t Token) String() string {
 
 // GoString implements fmt.GoStringer.
 func (t Token) GoString() string {
-	return fmt.Sprintf("{Type: %s, Lexeme: %q, Literal: %#v, Line: %d}", t.Type, t.Lexeme, t.Literal, t.Line)
+	return fmt.Sprintf("{Type: %s, Lexeme: %q, Literal: %#v, Line: %d, Column: %d}", t.Type, t.Lexeme, t.Literal, t.Line, t.Column)
 }
 
 var (
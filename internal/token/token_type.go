@@ -13,6 +13,9 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
+	COLON
 	COMMA
 	DOT
 	MINUS
@@ -30,48 +33,65 @@ const (
 	GREATER_EQUAL
 	LESS
 	LESS_EQUAL
+	ELLIPSIS
+	ARROW
+	STAR_STAR
+	PLUS_PLUS
+	MINUS_MINUS
 
 	// Literals.
 	IDENTIFIER
 	STRING
 	NUMBER
+	COMMENT
 
 	// Keywords.
 	AND
 	BREAK
+	CATCH
 	CONTINUE
 	CLASS
+	CONST
 	ELSE
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IN
+	IS
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
 	TRUE
+	TRY
 	VAR
 	WHILE
+	XOR
 )
 
 var tokenTypeStrings = map[TokenType]string{
 	EOF: "EOF",
 
 	// Single-character tokens.
-	LEFT_PAREN:  "LEFT_PAREN",
-	RIGHT_PAREN: "RIGHT_PAREN",
-	LEFT_BRACE:  "LEFT_BRACE",
-	RIGHT_BRACE: "RIGHT_BRACE",
-	COMMA:       "COMMA",
-	DOT:         "DOT",
-	MINUS:       "MINUS",
-	PLUS:        "PLUS",
-	SEMICOLON:   "SEMICOLON",
-	SLASH:       "SLASH",
-	STAR:        "STAR",
+	LEFT_PAREN:    "LEFT_PAREN",
+	RIGHT_PAREN:   "RIGHT_PAREN",
+	LEFT_BRACE:    "LEFT_BRACE",
+	RIGHT_BRACE:   "RIGHT_BRACE",
+	LEFT_BRACKET:  "LEFT_BRACKET",
+	RIGHT_BRACKET: "RIGHT_BRACKET",
+	COLON:         "COLON",
+	COMMA:         "COMMA",
+	DOT:           "DOT",
+	MINUS:         "MINUS",
+	PLUS:          "PLUS",
+	SEMICOLON:     "SEMICOLON",
+	SLASH:         "SLASH",
+	STAR:          "STAR",
 
 	// One or two character tokens.
 	BANG:          "BANG",
@@ -82,31 +102,45 @@ var tokenTypeStrings = map[TokenType]string{
 	GREATER_EQUAL: "GREATER_EQUAL",
 	LESS:          "LESS",
 	LESS_EQUAL:    "LESS_EQUAL",
+	ELLIPSIS:      "ELLIPSIS",
+	ARROW:         "ARROW",
+	STAR_STAR:     "STAR_STAR",
+	PLUS_PLUS:     "PLUS_PLUS",
+	MINUS_MINUS:   "MINUS_MINUS",
 
 	// Literals.
 	IDENTIFIER: "IDENTIFIER",
 	STRING:     "STRING",
 	NUMBER:     "NUMBER",
+	COMMENT:    "COMMENT",
 
 	// Keywords.
 	AND:      "AND",
 	BREAK:    "BREAK",
+	CATCH:    "CATCH",
 	CONTINUE: "CONTINUE",
 	CLASS:    "CLASS",
+	CONST:    "CONST",
 	ELSE:     "ELSE",
 	FALSE:    "FALSE",
+	FINALLY:  "FINALLY",
 	FUN:      "FUN",
 	FOR:      "FOR",
 	IF:       "IF",
+	IN:       "IN",
+	IS:       "IS",
 	NIL:      "NIL",
 	OR:       "OR",
 	PRINT:    "PRINT",
 	RETURN:   "RETURN",
 	SUPER:    "SUPER",
 	THIS:     "THIS",
+	THROW:    "THROW",
 	TRUE:     "TRUE",
+	TRY:      "TRY",
 	VAR:      "VAR",
 	WHILE:    "WHILE",
+	XOR:      "XOR",
 }
 
 func (t TokenType) String() string {
@@ -0,0 +1,371 @@
+package runner_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cli is the golox binary built for a test, run in the test temporary directory.
+type cli struct {
+	t   *testing.T
+	bin string
+	dir string
+}
+
+func newCli(t *testing.T) *cli {
+	t.Helper()
+
+	workDir, err := filepath.Abs(testProjectHomeDir)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "golox")
+	cmd := exec.Command("go", "build", "-o", bin, filepath.Join(workDir, "main.go"))
+	cmd.Dir = workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed with %v: %s", err, out)
+	}
+
+	return &cli{t: t, bin: bin, dir: dir}
+}
+
+// script writes the script file into the test directory, returns its path.
+func (c *cli) script(name, source string) string {
+	c.t.Helper()
+	path := filepath.Join(c.dir, name)
+	require.NoError(c.t, os.WriteFile(path, []byte(source), 0o600))
+	return path
+}
+
+// run runs golox with the arguments, returns stdout, stderr and the exit code.
+func (c *cli) run(stdin string, args ...string) (stdout, stderr string, code int) {
+	c.t.Helper()
+	cmd := exec.Command(c.bin, args...)
+	cmd.Dir = c.dir
+	cmd.Stdin = strings.NewReader(stdin)
+	outBuilder, errBuilder := new(strings.Builder), new(strings.Builder)
+	cmd.Stdout, cmd.Stderr = outBuilder, errBuilder
+	_ = cmd.Run()
+	return outBuilder.String(), errBuilder.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestCliCpuProfile(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+	script := c.script("script.lox", `print 1 + 2;`)
+
+	stdout, _, code := c.run("", script)
+	assert.Equal(t, "3\n", stdout)
+	assert.Equal(t, 0, code)
+	profiles, err := filepath.Glob(filepath.Join(c.dir, "*.prof"))
+	require.NoError(t, err)
+	assert.Empty(t, profiles, "no profile expected without -cpuprofile")
+
+	profile := filepath.Join(c.dir, "cpu.out")
+	stdout, _, code = c.run("", "-cpuprofile="+profile, script)
+	assert.Equal(t, "3\n", stdout)
+	assert.Equal(t, 0, code)
+	assert.FileExists(t, profile)
+}
+
+func TestCliUsage(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	_, stderr, code := c.run("", "-unknown")
+	assert.Equal(t, 64, code)
+	assert.Contains(t, stderr, "Usage: golox [flags] [script | -e code]")
+
+	_, stderr, code = c.run("", "a.lox", "b.lox")
+	assert.Equal(t, 64, code)
+	assert.Equal(t, "Usage: golox [flags] [script | -e code]\n", stderr)
+
+	_, stderr, code = c.run("", "-fmt")
+	assert.Equal(t, 64, code)
+	assert.Equal(t, "Usage: golox -fmt [-w] script\n", stderr)
+
+	_, stderr, code = c.run("", "-lint", "a.lox", "b.lox")
+	assert.Equal(t, 64, code)
+	assert.Equal(t, "Usage: golox -lint [-Werror] script\n", stderr)
+}
+
+func TestCliMissingFile(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	testcases := []struct {
+		name string
+		args []string
+	}{
+		{name: `run`, args: []string{"missing.lox"}},
+		{name: `format`, args: []string{"-fmt", "missing.lox"}},
+		{name: `lint`, args: []string{"-lint", "missing.lox"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, code := c.run("", tc.args...)
+			assert.Empty(t, stdout)
+			assert.Equal(t, "golox: cannot open 'missing.lox': no such file or directory\n", stderr)
+			assert.Equal(t, 66, code)
+		})
+	}
+}
+
+func TestCliByteOrderMark(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+	script := c.script("bom.lox", "\uFEFFprint \"bom\";\n")
+
+	stdout, stderr, code := c.run("", script)
+	assert.Equal(t, "bom\n", stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 0, code)
+
+	script = c.script("utf16.lox", "\xff\xfep\x00")
+	_, stderr, code = c.run("", script)
+	assert.Equal(t, "[line 1] Error (col 1): Source is not valid UTF-8.\n", stderr)
+	assert.Equal(t, 65, code)
+}
+
+func TestCliEvalCode(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	testcases := []struct {
+		name   string
+		args   []string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{name: `expression`, args: []string{"-e", "1+2;"}, stdout: "3\n"},
+		{name: `statements`, args: []string{"-e", `var a = "a"; a + "b";`}, stdout: "\"ab\"\n"},
+		{name: `with profile`, args: []string{"-profile=non-strict", "-e", "1;"}, stdout: "1\n"},
+		{
+			name: `warning`, args: []string{"-profile=non-strict", "-e", "{ var a = 1; } 2;"}, stdout: "2\n",
+			stderr: "WARN [line 1] Error at 'a' (col 7): Local variable is not used.\n",
+		},
+		{name: `parse error`, args: []string{"-e", "1 +;"}, stderr: "[line 1] Error at ';' (col 4): Expect expression.\n", code: 65},
+		{name: `runtime error`, args: []string{"-e", `-"a";`}, stderr: "Operand must be a number.\n[line 1] in script (col 1)\n", code: 70},
+		{name: `with script`, args: []string{"-e", "1;", "script.lox"}, stderr: "Usage: golox [flags] [script | -e code]\n", code: 64},
+		{name: `print statement`, args: []string{"-e", "print 1;"}, stdout: "1\n"},
+		{name: `declaration`, args: []string{"-e", "var a = 1;"}},
+		{name: `assignment`, args: []string{"-e", "var a = 1; a = 2;"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr, code := c.run("", tc.args...)
+			assert.Equal(t, tc.stdout, stdout)
+			assert.Equal(t, tc.stderr, stderr)
+			assert.Equal(t, tc.code, code)
+		})
+	}
+}
+
+func TestCliPrompt(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	testcases := []struct {
+		name   string
+		stdin  string
+		stdout string
+	}{
+		{name: `expression`, stdin: "1+2;\n", stdout: "3\n"},
+		{name: `declaration`, stdin: "var a=1;\na+1;\n", stdout: "2\n"},
+		{name: `assignment`, stdin: "var a=1;\na=2;\na;\n", stdout: "2\n"},
+		{name: `property assignment`, stdin: "class P {} var p = P();\np.x = 1;\np.x;\n", stdout: "1\n"},
+		{name: `postfix increment`, stdin: "var i=1;\ni++;\ni--;\ni;\n", stdout: "1\n"},
+		{name: `statements`, stdin: "print 1;\nfor (var i = 0; i < 2; i = i + 1) {}\nfun f() {}\nf();\n", stdout: "1\nnil\n"},
+		{name: `last statement`, stdin: "1; var b = 2;\nvar c = 3; c;\n", stdout: "3\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, code := c.run(tc.stdin)
+			assert.Equal(t, tc.stdout, stdout)
+			assert.Empty(t, stderr)
+			assert.Equal(t, 0, code)
+		})
+	}
+}
+
+func TestCliDumpTokens(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+	expected := `{Type: NUMBER, Lexeme: "1", Literal: 1, Line: 1, Column: 1}
+{Type: PLUS, Lexeme: "+", Literal: <nil>, Line: 1, Column: 2}
+{Type: NUMBER, Lexeme: "2", Literal: 2, Line: 1, Column: 3}
+{Type: SEMICOLON, Lexeme: ";", Literal: <nil>, Line: 1, Column: 4}
+{Type: EOF, Lexeme: "", Literal: <nil>, Line: 1, Column: 5}
+`
+
+	stdout, stderr, code := c.run("", "--dump-tokens", "-e", "1+2;")
+	assert.Equal(t, expected, stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 0, code)
+
+	stdout, _, code = c.run("", "--dump-tokens", c.script("script.lox", "1+2;"))
+	assert.Equal(t, expected, stdout)
+	assert.Equal(t, 0, code)
+
+	// not parsed
+	stdout, _, code = c.run("", "--dump-tokens", "-e", "1+;")
+	assert.Contains(t, stdout, "PLUS")
+	assert.Equal(t, 0, code)
+}
+
+func TestCliDumpAst(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	stdout, stderr, code := c.run("", "--dump-ast", "-e", "var a = 1 + 2; print a;")
+	assert.Equal(t, "(var a (+ 1 2))\n(print a)\n", stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 0, code)
+
+	// not resolved, nor executed
+	stdout, _, code = c.run("", "--dump-ast", c.script("script.lox", "print undefined;"))
+	assert.Equal(t, "(print undefined)\n", stdout)
+	assert.Equal(t, 0, code)
+
+	_, _, code = c.run("", "--dump-ast", "-e", "var;")
+	assert.Equal(t, 65, code)
+}
+
+func TestCliNoBuiltins(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	_, stderr, code := c.run("", "-no-builtins", "-e", "clock();")
+	assert.Equal(t, "Undefined variable 'clock'.\n[line 1] in script (col 1)\n", stderr)
+	assert.Equal(t, 70, code)
+
+	stdout, _, code := c.run("", "-e", "type(clock);")
+	assert.Equal(t, "\"function\"\n", stdout)
+	assert.Equal(t, 0, code)
+}
+
+func TestCliExit(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	stdout, stderr, code := c.run("", c.script("script.lox", "print 1; exit(3); print 2;"))
+	assert.Equal(t, "1\n", stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 3, code)
+
+	_, stderr, code = c.run("", "-e", "exit(256);")
+	assert.Equal(t, "Exit code must be an integer between 0 and 255.\n[line 1] in script (col 9)\n", stderr)
+	assert.Equal(t, 70, code)
+}
+
+func TestCliStringConcat(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	stdout, _, code := c.run("", "-loose-concat", "-e", `"x=" + 5;`)
+	assert.Equal(t, "\"x=5\"\n", stdout)
+	assert.Equal(t, 0, code)
+
+	_, stderr, code := c.run("", "-loose-concat", "-e", `true + nil;`)
+	assert.Equal(t, "Operands must be two numbers or two strings.\n[line 1] in script (col 6)\n", stderr)
+	assert.Equal(t, 70, code)
+
+	// strict by default, the lint profile doesn't change it
+	for _, profile := range []string{"default", "strict", "non-strict"} {
+		_, stderr, code = c.run("", "-profile="+profile, "-e", `"x=" + 5;`)
+		assert.Equal(t, "Operands must be two numbers or two strings.\n[line 1] in script (col 6)\n", stderr, profile)
+		assert.Equal(t, 70, code, profile)
+	}
+}
+
+func TestCliDivisionByZero(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	for _, profile := range []string{"default", "non-strict"} {
+		_, stderr, code := c.run("", "-profile="+profile, "-e", `1 / 0;`)
+		assert.Equal(t, "Division by zero.\n[line 1] in script (col 3)\n", stderr, profile)
+		assert.Equal(t, 70, code, profile)
+	}
+
+	stdout, _, code := c.run("", "-allow-infinity", "-e", `[1 / 0, 0 / 0];`)
+	assert.Equal(t, "[Infinity, NaN]\n", stdout)
+	assert.Equal(t, 0, code)
+}
+
+func TestCliFormat(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	messy := "var   a=1 ;\nif(a>0){print a;}\n  fun  f( x ){return x*2;}\n"
+	formatted := "var a = 1;\nif (a > 0) {\n  print a;\n}\nfun f(x) {\n  return x * 2;\n}\n"
+
+	path := c.script("messy.lox", messy)
+	stdout, stderr, code := c.run("", "-fmt", path)
+	assert.Equal(t, formatted, stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 0, code)
+
+	stdout, _, code = c.run("", "-fmt", "-w", path)
+	assert.Empty(t, stdout)
+	assert.Equal(t, 0, code)
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(written))
+
+	require.NoError(t, os.Chmod(path, 0o640))
+	_, _, code = c.run("", "-fmt", "-w", path)
+	assert.Equal(t, 0, code)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	broken := c.script("broken.lox", "var = 1;")
+	_, stderr, code = c.run("", "-fmt", "-w", broken)
+	assert.Equal(t, "[line 1] Error at '=' (col 5): Expect variable name.\n", stderr)
+	assert.Equal(t, 65, code)
+	written, err = os.ReadFile(broken)
+	require.NoError(t, err)
+	assert.Equal(t, "var = 1;", string(written))
+}
+
+func TestCliLint(t *testing.T) {
+	t.Parallel()
+	c := newCli(t)
+
+	warning := "[line 1, col 5] warning: Constant condition, the branch is never executed. (dead-branch)\n"
+	script := c.script("warning.lox", "if (false) print 1;\n")
+	stdout, stderr, code := c.run("", "-lint", script)
+	assert.Equal(t, warning, stdout)
+	assert.Empty(t, stderr)
+	assert.Equal(t, 0, code)
+
+	stdout, _, code = c.run("", "-lint", "-Werror", script)
+	assert.Equal(t, warning, stdout)
+	assert.Equal(t, 65, code)
+
+	script = c.script("error.lox", "if (false) print 1;\nfun f() { var a = 1; }\n")
+	stdout, _, code = c.run("", "-lint", script)
+	assert.Equal(t, warning+"[line 2, col 15] error: Local variable is not used. (unused-variable)\n", stdout)
+	assert.Equal(t, 65, code)
+
+	stdout, _, code = c.run("", "-lint", "-profile=non-strict", script)
+	assert.Equal(t, warning+"[line 2, col 15] warning: Local variable is not used. (unused-variable)\n", stdout)
+	assert.Equal(t, 0, code)
+
+	stdout, _, code = c.run("", "-lint", c.script("clean.lox", "print 1;\n"))
+	assert.Empty(t, stdout)
+	assert.Equal(t, 0, code)
+}
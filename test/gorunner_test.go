@@ -13,12 +13,15 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/maps"
+
+	"github.com/leonardinius/golox/internal/loxerrors"
 )
 
 const (
@@ -34,6 +37,8 @@ var (
 	syntaxErrorPattern          = regexp.MustCompile(`\[.*line (\d+)\] (Error.+)`)
 	stackTracePattern           = regexp.MustCompile(`\[line (\d+)\]`)
 	nonTestPattern              = regexp.MustCompile(`// nontest`)
+	// The go errors carry the column after the location, the expectations don't.
+	errorColumnPattern = regexp.MustCompile(` \(col \d+\):`)
 )
 
 type Runner struct {
@@ -244,6 +249,10 @@ func (t *Test) run() []string {
 
 	outputLines := strings.Split(stdout.String(), "\n")
 	errorLines := strings.Split(stderr.String(), "\n")
+	// the warnings are not expected by the suite, e.g. the unused locals demoted by the non-strict profile
+	errorLines = slices.DeleteFunc(errorLines, func(line string) bool {
+		return strings.HasPrefix(line, loxerrors.PrefixWarning+" ")
+	})
 
 	if t.expectedRuntimeError != "" {
 		t.validateRuntimeError(errorLines)
@@ -290,7 +299,7 @@ func (t *Test) validateCompileErrors(errorLines []string) {
 	for _, line := range errorLines {
 		match := syntaxErrorPattern.FindStringSubmatch(line)
 		if match != nil {
-			errorMsg := fmt.Sprintf("[line %s] %s", match[1], match[2])
+			errorMsg := fmt.Sprintf("[line %s] %s", match[1], errorColumnPattern.ReplaceAllString(match[2], ":"))
 			if _, ok := t.expectedErrors[errorMsg]; ok {
 				foundErrors[errorMsg] = true
 			} else {
@@ -402,7 +411,7 @@ func (r *Runner) InitSuites() {
 			language:    "go",
 			executable:  goloxBin,
 			testsGroups: suiteTests,
-			args:        []string{"-profile=non-strict"},
+			args:        []string{"-profile=non-strict", "-allow-infinity"},
 		}
 		r.goSuites = append(r.goSuites, name)
 	}
@@ -413,7 +422,7 @@ func (r *Runner) InitSuites() {
 		"test/expressions": "skip",
 	}
 
-	// Go doesn't correctly implement IEEE equality on boxed doubles.
+	// The interpreter compares numbers with the IEEE semantics, NaN equality passes.
 	goNaNEquality := map[string]string{
 		// "test/number/nan_equality.lox": "skip",
 	}
@@ -434,11 +443,19 @@ func (r *Runner) InitSuites() {
 		"test/field/set_on_class.lox": "skip",
 	}
 
+	// `{}` is an empty map literal expression, see test/for/map_literal_*.lox for what these loops do instead.
+	goloxMapLiterals := map[string]string{
+		"test/for/statement_condition.lox":   "skip",
+		"test/for/statement_increment.lox":   "skip",
+		"test/for/statement_initializer.lox": "skip",
+	}
+
 	golox("golox",
 		map[string]string{"test": "pass"},
 		earlyChapters,
 		goNaNEquality,
 		noGoLimits,
 		goloxClassAttributesAccessErrors,
+		goloxMapLiterals,
 	)
 }
@@ -22,17 +22,21 @@ func Main(args []string) int {
 	packageName := args[2]
 
 	if err := defineAst(expressionsOutFile, packageName, "Expr",
+		"ExprArray    : Elements []Expr",
 		"ExprAssign   : Name *token.Token, Value Expr",
 		"ExprBinary   : Left Expr, Operator *token.Token, Right Expr",
 		"ExprCall     : Callee Expr, CloseParen *token.Token, Arguments []Expr",
-		"ExprFunction : Parameters []*token.Token, Body []Stmt",
+		"ExprFunction : Parameters []*token.Token, Body []Stmt, IsVariadic bool, InferredName *token.Token",
 		"ExprGet      : Instance Expr, Name *token.Token",
 		"ExprGrouping : Expression Expr",
-		"ExprLiteral  : Value any",
+		"ExprLiteral  : Value any, Token *token.Token",
 		"ExprLogical  : Left Expr, Operator *token.Token, Right Expr",
+		"ExprMap      : Brace *token.Token, Keys []Expr, Values []Expr",
+		"ExprPostfix  : Target Expr, Operator *token.Token",
 		"ExprSet      : Instance Expr, Name *token.Token, Value Expr",
 		"ExprSuper    : Keyword *token.Token, Method *token.Token",
 		"ExprThis     : Keyword *token.Token",
+		"ExprTypeCheck: Instance Expr, Keyword *token.Token, Class Expr",
 		"ExprUnary    : Operator *token.Token, Right Expr",
 		"ExprVariable : Name *token.Token",
 	); err != nil {
@@ -42,17 +46,21 @@ func Main(args []string) int {
 
 	if err := defineAst(statementsOutFile, packageName, "Stmt",
 		"StmtBlock      : Statements []Stmt",
-		"StmtClass      : Name *token.Token, SuperClass *ExprVariable, Methods []*StmtFunction, ClassMethods []*StmtFunction",
+		"StmtClass      : Name *token.Token, SuperClass *ExprVariable, Fields []*StmtVar, Methods []*StmtFunction, ClassMethods []*StmtFunction",
 		"StmtExpression : Expression Expr",
-		"StmtFunction   : Name *token.Token, Fn *ExprFunction",
+		"StmtFunction   : Name *token.Token, Fn *ExprFunction, IsGetter bool",
 		"StmtIf         : Condition Expr, ThenBranch Stmt, ElseBranch Stmt",
-		"StmtPrint      : Expression Expr",
+		"StmtPrint      : Keyword *token.Token, Expressions []Expr",
 		"StmtReturn     : Keyword  *token.Token, Value Expr",
-		"StmtVar        : Name *token.Token, Initializer Expr",
-		"StmtWhile      : Condition Expr, Body Stmt",
-		"StmtFor        : Initializer Stmt, Condition Expr, Increment Expr, Body Stmt",
-		"StmtBreak      :",
-		"StmtContinue   :",
+		"StmtVar        : Name *token.Token, Initializer Expr, IsConst bool",
+		"StmtWhile      : Condition Expr, Body Stmt, Label *token.Token",
+		"StmtFor        : Initializer Stmt, Condition Expr, Increment Expr, Body Stmt, Label *token.Token",
+		"StmtForEach    : Name *token.Token, In *token.Token, Iterable Expr, Body Stmt, Label *token.Token",
+		"StmtTry        : Body []Stmt, CatchName *token.Token, CatchBody []Stmt, FinallyBody []Stmt",
+		"StmtThrow      : Keyword *token.Token, Value Expr",
+		"StmtBreak      : Keyword *token.Token, Label *token.Token",
+		"StmtContinue   : Keyword *token.Token, Label *token.Token",
+		"StmtEmpty      : Semicolon *token.Token",
 	); err != nil {
 		fmt.Printf("Error: %v", err)
 		return 1